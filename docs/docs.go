@@ -0,0 +1,9 @@
+// Package docs bundles the versioned action catalog snapshot into the binary.
+package docs
+
+import _ "embed"
+
+// ActionsJSON is the content of docs/actions.json.
+//
+//go:embed actions.json
+var ActionsJSON []byte
@@ -39,6 +39,10 @@ type Action struct {
 	Description string             `json:"description,omitempty"`
 	Parameters  []ActionParameter  `json:"parameters,omitempty"`
 	RequestBody *ActionRequestBody `json:"request_body,omitempty"`
+	Destructive bool               `json:"destructive,omitempty"`
+	// NoMetadata marks actions from a catalog that only records the endpoint, such as
+	// the embedded snapshot: their query parameters and body cannot be validated.
+	NoMetadata bool `json:"no_metadata,omitempty"`
 }
 
 // ActionParameter describes an accepted parameter for an action.
@@ -91,8 +95,8 @@ type Catalog struct {
 	Actions     []Action  `json:"actions"`
 }
 
-// LoadCatalog fetches Holded docs and builds an action catalog from all published APIs.
-func LoadCatalog(ctx context.Context, httpClient *http.Client) (Catalog, error) {
+// FetchCatalog fetches Holded docs and builds an action catalog from all published APIs.
+func FetchCatalog(ctx context.Context, httpClient *http.Client) (Catalog, error) {
 	if httpClient == nil {
 		httpClient = &http.Client{Timeout: 20 * time.Second}
 	}
@@ -110,30 +114,10 @@ func LoadCatalog(ctx context.Context, httpClient *http.Client) (Catalog, error)
 		}
 	}
 
-	actions := make([]Action, 0, len(actionsByKey))
-	for _, action := range actionsByKey {
-		actions = append(actions, action)
-	}
-
-	sort.Slice(actions, func(i, j int) bool {
-		if actions[i].API != actions[j].API {
-			return actions[i].API < actions[j].API
-		}
-		if actions[i].Path != actions[j].Path {
-			return actions[i].Path < actions[j].Path
-		}
-		if actions[i].Method != actions[j].Method {
-			return actions[i].Method < actions[j].Method
-		}
-		return actions[i].OperationID < actions[j].OperationID
-	})
-
-	ensureUniqueIDs(actions)
-
 	return Catalog{
 		GeneratedAt: time.Now().UTC(),
 		Source:      docsBaseURL + "/reference/api-key",
-		Actions:     actions,
+		Actions:     sortedActions(actionsByKey),
 	}, nil
 }
 
@@ -245,14 +229,18 @@ func buildActionsFromProps(propsJSON []byte) ([]Action, error) {
 		return nil, err
 	}
 
-	apiName := strings.TrimSpace(props.Document.API.Schema.Info.Title)
+	return buildActionsFromSchema(props.Document.API.Schema)
+}
+
+func buildActionsFromSchema(schema schemaSpec) ([]Action, error) {
+	apiName := strings.TrimSpace(schema.Info.Title)
 	if apiName == "" {
 		return nil, fmt.Errorf("missing API title in docs payload")
 	}
 
 	serverPrefix := "/"
-	if len(props.Document.API.Schema.Servers) > 0 {
-		serverURL := strings.TrimSpace(props.Document.API.Schema.Servers[0].URL)
+	if len(schema.Servers) > 0 {
+		serverURL := strings.TrimSpace(schema.Servers[0].URL)
 		if serverURL != "" {
 			u, err := url.Parse(serverURL)
 			if err == nil {
@@ -277,7 +265,7 @@ func buildActionsFromProps(propsJSON []byte) ([]Action, error) {
 	}
 
 	var actions []Action
-	for pathValue, item := range props.Document.API.Schema.Paths {
+	for pathValue, item := range schema.Paths {
 		fullPath := joinPath(serverPrefix, pathValue)
 		pathParameters := decodeParameters(item["parameters"])
 
@@ -315,6 +303,29 @@ func buildActionsFromProps(propsJSON []byte) ([]Action, error) {
 	return actions, nil
 }
 
+func sortedActions(actionsByKey map[string]Action) []Action {
+	actions := make([]Action, 0, len(actionsByKey))
+	for _, action := range actionsByKey {
+		actions = append(actions, action)
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		if actions[i].API != actions[j].API {
+			return actions[i].API < actions[j].API
+		}
+		if actions[i].Path != actions[j].Path {
+			return actions[i].Path < actions[j].Path
+		}
+		if actions[i].Method != actions[j].Method {
+			return actions[i].Method < actions[j].Method
+		}
+		return actions[i].OperationID < actions[j].OperationID
+	})
+
+	ensureUniqueIDs(actions)
+	return actions
+}
+
 func ensureUniqueIDs(actions []Action) {
 	seen := make(map[string]int)
 	for i := range actions {
@@ -623,8 +634,14 @@ func convertEnum(values []any) []string {
 	return result
 }
 
-// ValidateBodyParameters validates a JSON body against the action body schema (top-level fields).
+// ValidateBodyParameters validates a JSON body against the action body schema,
+// walking nested objects and array items. Issue fields are JSONPath-style
+// locations such as $.items[3].units. Actions marked NoMetadata accept any body.
 func ValidateBodyParameters(action Action, body []byte) []ValidationIssue {
+	if action.NoMetadata {
+		return nil
+	}
+
 	requestBody := action.RequestBody
 	trimmed := strings.TrimSpace(string(body))
 
@@ -664,12 +681,25 @@ func ValidateBodyParameters(action Action, body []byte) []ValidationIssue {
 		}}
 	}
 
-	if len(requestBody.Fields) == 0 {
+	issues := validateObject("$", obj, requestBody.Fields)
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Field != issues[j].Field {
+			return issues[i].Field < issues[j].Field
+		}
+		return issues[i].Message < issues[j].Message
+	})
+
+	return issues
+}
+
+func validateObject(path string, obj map[string]any, fields []ActionBodyField) []ValidationIssue {
+	if len(fields) == 0 {
 		return nil
 	}
 
-	allowed := make(map[string]ActionBodyField, len(requestBody.Fields))
-	for _, field := range requestBody.Fields {
+	allowed := make(map[string]ActionBodyField, len(fields))
+	for _, field := range fields {
 		allowed[field.Name] = field
 	}
 
@@ -678,54 +708,66 @@ func ValidateBodyParameters(action Action, body []byte) []ValidationIssue {
 		field, exists := allowed[key]
 		if !exists {
 			issues = append(issues, ValidationIssue{
-				Field:   "$." + key,
+				Field:   path + "." + key,
 				Message: "unknown body field",
 			})
 			continue
 		}
 
-		if field.Type != "" && !matchesType(value, field.Type) {
-			issues = append(issues, ValidationIssue{
-				Field:   "$." + key,
-				Message: fmt.Sprintf("expected %s", field.Type),
-			})
-		}
-
-		if len(field.Enum) > 0 {
-			stringValue := fmt.Sprint(value)
-			valid := false
-			for _, enumValue := range field.Enum {
-				if stringValue == enumValue {
-					valid = true
-					break
-				}
-			}
-			if !valid {
-				issues = append(issues, ValidationIssue{
-					Field:   "$." + key,
-					Message: fmt.Sprintf("value must be one of: %s", strings.Join(field.Enum, ", ")),
-				})
-			}
-		}
+		issues = append(issues, validateValue(path+"."+key, value, field.Type, field.Enum, field.Fields, field.Item)...)
 	}
 
-	for _, field := range requestBody.Fields {
+	for _, field := range fields {
 		if field.Required {
 			if _, exists := obj[field.Name]; !exists {
 				issues = append(issues, ValidationIssue{
-					Field:   "$." + field.Name,
+					Field:   path + "." + field.Name,
 					Message: "required body field is missing",
 				})
 			}
 		}
 	}
 
-	sort.Slice(issues, func(i, j int) bool {
-		if issues[i].Field != issues[j].Field {
-			return issues[i].Field < issues[j].Field
+	return issues
+}
+
+func validateValue(path string, value any, kind string, enum []string, fields []ActionBodyField, item *ActionBodyItem) []ValidationIssue {
+	if kind != "" && !matchesType(value, kind) {
+		return []ValidationIssue{{
+			Field:   path,
+			Message: fmt.Sprintf("expected %s", kind),
+		}}
+	}
+
+	var issues []ValidationIssue
+	if len(enum) > 0 {
+		stringValue := fmt.Sprint(value)
+		valid := false
+		for _, enumValue := range enum {
+			if stringValue == enumValue {
+				valid = true
+				break
+			}
 		}
-		return issues[i].Message < issues[j].Message
-	})
+		if !valid {
+			issues = append(issues, ValidationIssue{
+				Field:   path,
+				Message: fmt.Sprintf("value must be one of: %s", strings.Join(enum, ", ")),
+			})
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		issues = append(issues, validateObject(path, v, fields)...)
+	case []any:
+		if item != nil {
+			for i, element := range v {
+				elementPath := fmt.Sprintf("%s[%d]", path, i)
+				issues = append(issues, validateValue(elementPath, element, item.Type, item.Enum, item.Fields, item.Item)...)
+			}
+		}
+	}
 
 	return issues
 }
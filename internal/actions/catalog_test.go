@@ -215,3 +215,57 @@ func TestValidateBodyParameters(t *testing.T) {
 		t.Fatalf("expected 3 validation issues, got %+v", issues)
 	}
 }
+
+func TestValidateBodyParametersNested(t *testing.T) {
+	t.Parallel()
+
+	action := Action{
+		ID: "invoice.create-document",
+		RequestBody: &ActionRequestBody{
+			Fields: []ActionBodyField{
+				{Name: "contactId", Type: "string"},
+				{
+					Name: "items",
+					Type: "array",
+					Item: &ActionBodyItem{
+						Type: "object",
+						Fields: []ActionBodyField{
+							{Name: "name", Required: true, Type: "string"},
+							{Name: "units", Type: "number"},
+							{Name: "tags", Type: "array", Item: &ActionBodyItem{Type: "string", Enum: []string{"a", "b"}}},
+						},
+					},
+				},
+				{
+					Name: "shipping",
+					Type: "object",
+					Fields: []ActionBodyField{
+						{Name: "country", Required: true, Type: "string", Enum: []string{"ES", "FR"}},
+					},
+				},
+			},
+		},
+	}
+
+	issues := ValidateBodyParameters(action, []byte(`{"contactId":"c1","items":[{"name":"x","units":2,"tags":["a"]}],"shipping":{"country":"ES"}}`))
+	if len(issues) != 0 {
+		t.Fatalf("expected no validation issues, got %+v", issues)
+	}
+
+	issues = ValidateBodyParameters(action, []byte(`{"items":[{"name":"x"},{"name":"y"},{"name":"z"},{"units":"2","colour":"red","tags":["c"]}],"shipping":{"country":"DE"}}`))
+	want := []ValidationIssue{
+		{Field: "$.items[3].colour", Message: "unknown body field"},
+		{Field: "$.items[3].name", Message: "required body field is missing"},
+		{Field: "$.items[3].tags[0]", Message: "value must be one of: a, b"},
+		{Field: "$.items[3].units", Message: "expected number"},
+		{Field: "$.shipping.country", Message: "value must be one of: ES, FR"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("issues = %+v, want %+v", issues, want)
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Fatalf("issues[%d] = %+v, want %+v", i, issues[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Change kinds reported by DiffCatalogs.
+const (
+	ChangeMethod              = "method"
+	ChangePath                = "path"
+	ChangeParameterAdded      = "parameter_added"
+	ChangeParameterRemoved    = "parameter_removed"
+	ChangeParameterRequired   = "parameter_required"
+	ChangeParameterType       = "parameter_type"
+	ChangeParameterEnum       = "parameter_enum"
+	ChangeRequestBodyAdded    = "request_body_added"
+	ChangeRequestBodyRemoved  = "request_body_removed"
+	ChangeRequestBodyRequired = "request_body_required"
+	ChangeBodyFieldAdded      = "body_field_added"
+	ChangeBodyFieldRemoved    = "body_field_removed"
+	ChangeBodyFieldRequired   = "body_field_required"
+	ChangeBodyFieldType       = "body_field_type"
+	ChangeBodyFieldEnum       = "body_field_enum"
+)
+
+// CatalogDiff describes the differences between two catalogs, matching actions by id.
+type CatalogDiff struct {
+	Added    []ActionRef  `json:"added"`
+	Removed  []ActionRef  `json:"removed"`
+	Changed  []ActionDiff `json:"changed"`
+	Breaking int          `json:"breaking"`
+}
+
+// ActionRef identifies an added or removed action.
+type ActionRef struct {
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// ActionDiff lists the changes of an action present in both catalogs.
+type ActionDiff struct {
+	ID      string         `json:"id"`
+	Changes []ActionChange `json:"changes"`
+}
+
+// ActionChange is a single metadata change. Target names the parameter (in:name) or body field ($.a.b[].c).
+type ActionChange struct {
+	Kind     string `json:"kind"`
+	Target   string `json:"target,omitempty"`
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new,omitempty"`
+	Breaking bool   `json:"breaking"`
+}
+
+// Empty reports whether both catalogs describe the same actions.
+func (d CatalogDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffCatalogs compares two catalogs and flags changes that can break existing callers.
+func DiffCatalogs(oldCatalog, newCatalog Catalog) CatalogDiff {
+	oldByID := make(map[string]Action, len(oldCatalog.Actions))
+	for _, action := range oldCatalog.Actions {
+		oldByID[action.ID] = action
+	}
+	newByID := make(map[string]Action, len(newCatalog.Actions))
+	for _, action := range newCatalog.Actions {
+		newByID[action.ID] = action
+	}
+
+	diff := CatalogDiff{
+		Added:   []ActionRef{},
+		Removed: []ActionRef{},
+		Changed: []ActionDiff{},
+	}
+
+	for id, action := range newByID {
+		if _, exists := oldByID[id]; !exists {
+			diff.Added = append(diff.Added, ActionRef{ID: id, Method: action.Method, Path: action.Path})
+		}
+	}
+
+	for id, oldAction := range oldByID {
+		newAction, exists := newByID[id]
+		if !exists {
+			diff.Removed = append(diff.Removed, ActionRef{ID: id, Method: oldAction.Method, Path: oldAction.Path})
+			diff.Breaking++
+			continue
+		}
+
+		changes := diffAction(oldAction, newAction)
+		if len(changes) == 0 {
+			continue
+		}
+		for _, change := range changes {
+			if change.Breaking {
+				diff.Breaking++
+			}
+		}
+		diff.Changed = append(diff.Changed, ActionDiff{ID: id, Changes: changes})
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].ID < diff.Added[j].ID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].ID < diff.Removed[j].ID })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].ID < diff.Changed[j].ID })
+
+	return diff
+}
+
+func diffAction(oldAction, newAction Action) []ActionChange {
+	var changes []ActionChange
+
+	if oldAction.Method != newAction.Method {
+		changes = append(changes, ActionChange{Kind: ChangeMethod, Old: oldAction.Method, New: newAction.Method, Breaking: true})
+	}
+	if oldAction.Path != newAction.Path {
+		changes = append(changes, ActionChange{Kind: ChangePath, Old: oldAction.Path, New: newAction.Path, Breaking: true})
+	}
+
+	// Without metadata on one side (the embedded snapshot) every parameter and field
+	// would look added or removed, so only the endpoint is compared.
+	if oldAction.NoMetadata || newAction.NoMetadata {
+		return changes
+	}
+	changes = append(changes, diffParameters(oldAction.Parameters, newAction.Parameters)...)
+	changes = append(changes, diffRequestBody(oldAction.RequestBody, newAction.RequestBody)...)
+
+	return changes
+}
+
+func diffParameters(oldParams, newParams []ActionParameter) []ActionChange {
+	key := func(p ActionParameter) string { return p.In + ":" + p.Name }
+
+	oldByKey := make(map[string]ActionParameter, len(oldParams))
+	for _, p := range oldParams {
+		oldByKey[key(p)] = p
+	}
+	newByKey := make(map[string]ActionParameter, len(newParams))
+	for _, p := range newParams {
+		newByKey[key(p)] = p
+	}
+
+	var changes []ActionChange
+	for _, target := range sortedKeys(oldByKey, newByKey) {
+		oldParam, inOld := oldByKey[target]
+		newParam, inNew := newByKey[target]
+
+		switch {
+		case !inOld:
+			changes = append(changes, ActionChange{
+				Kind:     ChangeParameterAdded,
+				Target:   target,
+				New:      requiredLabel(newParam.Required),
+				Breaking: newParam.Required,
+			})
+		case !inNew:
+			changes = append(changes, ActionChange{Kind: ChangeParameterRemoved, Target: target, Breaking: true})
+		default:
+			if oldParam.Required != newParam.Required {
+				changes = append(changes, ActionChange{
+					Kind:     ChangeParameterRequired,
+					Target:   target,
+					Old:      requiredLabel(oldParam.Required),
+					New:      requiredLabel(newParam.Required),
+					Breaking: newParam.Required,
+				})
+			}
+			if oldParam.Type != newParam.Type {
+				changes = append(changes, ActionChange{Kind: ChangeParameterType, Target: target, Old: oldParam.Type, New: newParam.Type, Breaking: true})
+			}
+			if change, ok := diffEnum(ChangeParameterEnum, target, oldParam.Enum, newParam.Enum); ok {
+				changes = append(changes, change)
+			}
+		}
+	}
+
+	return changes
+}
+
+func diffRequestBody(oldBody, newBody *ActionRequestBody) []ActionChange {
+	switch {
+	case oldBody == nil && newBody == nil:
+		return nil
+	case oldBody == nil:
+		return []ActionChange{{
+			Kind:     ChangeRequestBodyAdded,
+			Target:   "$",
+			New:      requiredLabel(newBody.Required),
+			Breaking: newBody.Required,
+		}}
+	case newBody == nil:
+		return []ActionChange{{Kind: ChangeRequestBodyRemoved, Target: "$", Breaking: true}}
+	}
+
+	var changes []ActionChange
+	if oldBody.Required != newBody.Required {
+		changes = append(changes, ActionChange{
+			Kind:     ChangeRequestBodyRequired,
+			Target:   "$",
+			Old:      requiredLabel(oldBody.Required),
+			New:      requiredLabel(newBody.Required),
+			Breaking: newBody.Required,
+		})
+	}
+
+	return append(changes, diffBodyFields("$", oldBody.Fields, newBody.Fields)...)
+}
+
+func diffBodyFields(prefix string, oldFields, newFields []ActionBodyField) []ActionChange {
+	oldByName := make(map[string]ActionBodyField, len(oldFields))
+	for _, field := range oldFields {
+		oldByName[field.Name] = field
+	}
+	newByName := make(map[string]ActionBodyField, len(newFields))
+	for _, field := range newFields {
+		newByName[field.Name] = field
+	}
+
+	var changes []ActionChange
+	for _, name := range sortedKeys(oldByName, newByName) {
+		target := prefix + "." + name
+		oldField, inOld := oldByName[name]
+		newField, inNew := newByName[name]
+
+		switch {
+		case !inOld:
+			changes = append(changes, ActionChange{
+				Kind:     ChangeBodyFieldAdded,
+				Target:   target,
+				New:      requiredLabel(newField.Required),
+				Breaking: newField.Required,
+			})
+		case !inNew:
+			changes = append(changes, ActionChange{Kind: ChangeBodyFieldRemoved, Target: target, Breaking: true})
+		default:
+			if oldField.Required != newField.Required {
+				changes = append(changes, ActionChange{
+					Kind:     ChangeBodyFieldRequired,
+					Target:   target,
+					Old:      requiredLabel(oldField.Required),
+					New:      requiredLabel(newField.Required),
+					Breaking: newField.Required,
+				})
+			}
+			changes = append(changes, diffSchema(target, oldField.Type, newField.Type, oldField.Enum, newField.Enum)...)
+			// A type change already covers the nested fields and items it replaces.
+			if oldField.Type == newField.Type {
+				changes = append(changes, diffBodyFields(target, oldField.Fields, newField.Fields)...)
+				changes = append(changes, diffBodyItems(target+"[]", oldField.Item, newField.Item)...)
+			}
+		}
+	}
+
+	return changes
+}
+
+func diffBodyItems(target string, oldItem, newItem *ActionBodyItem) []ActionChange {
+	if oldItem == nil {
+		oldItem = &ActionBodyItem{}
+	}
+	if newItem == nil {
+		newItem = &ActionBodyItem{}
+	}
+
+	changes := diffSchema(target, oldItem.Type, newItem.Type, oldItem.Enum, newItem.Enum)
+	if oldItem.Type != newItem.Type {
+		return changes
+	}
+	changes = append(changes, diffBodyFields(target, oldItem.Fields, newItem.Fields)...)
+	if oldItem.Item != nil || newItem.Item != nil {
+		changes = append(changes, diffBodyItems(target+"[]", oldItem.Item, newItem.Item)...)
+	}
+	return changes
+}
+
+func diffSchema(target, oldType, newType string, oldEnum, newEnum []string) []ActionChange {
+	var changes []ActionChange
+	if oldType != newType {
+		changes = append(changes, ActionChange{Kind: ChangeBodyFieldType, Target: target, Old: oldType, New: newType, Breaking: true})
+	}
+	if change, ok := diffEnum(ChangeBodyFieldEnum, target, oldEnum, newEnum); ok {
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// diffEnum reports enum changes; removing values (or constraining a free value) is breaking.
+// Enums are compared as sets, so reordering values is not a change.
+func diffEnum(kind, target string, oldEnum, newEnum []string) (ActionChange, bool) {
+	oldSet := make(map[string]bool, len(oldEnum))
+	for _, value := range oldEnum {
+		oldSet[value] = true
+	}
+	newSet := make(map[string]bool, len(newEnum))
+	for _, value := range newEnum {
+		newSet[value] = true
+	}
+	if sameSet(oldSet, newSet) {
+		return ActionChange{}, false
+	}
+
+	breaking := len(oldEnum) == 0 && len(newEnum) > 0
+	for _, value := range oldEnum {
+		if len(newEnum) > 0 && !newSet[value] {
+			breaking = true
+			break
+		}
+	}
+
+	return ActionChange{
+		Kind:     kind,
+		Target:   target,
+		Old:      strings.Join(oldEnum, ","),
+		New:      strings.Join(newEnum, ","),
+		Breaking: breaking,
+	}, true
+}
+
+func sameSet(left, right map[string]bool) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for value := range left {
+		if !right[value] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[T any](left, right map[string]T) []string {
+	seen := make(map[string]bool, len(left)+len(right))
+	keys := make([]string, 0, len(left)+len(right))
+	for key := range left {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for key := range right {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func requiredLabel(required bool) string {
+	if required {
+		return "required"
+	}
+	return "optional"
+}
+
+// String renders a change as a single human-readable line.
+func (c ActionChange) String() string {
+	line := c.Kind
+	if c.Target != "" {
+		line += " " + c.Target
+	}
+	switch {
+	case c.Old != "" && c.New != "":
+		line += fmt.Sprintf(": %s -> %s", c.Old, c.New)
+	case c.Old != "":
+		line += fmt.Sprintf(": was %s", c.Old)
+	case c.New != "":
+		line += fmt.Sprintf(": %s", c.New)
+	}
+	if c.Breaking {
+		line = "[breaking] " + line
+	}
+	return line
+}
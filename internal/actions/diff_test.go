@@ -0,0 +1,148 @@
+package actions
+
+import "testing"
+
+func TestDiffCatalogs(t *testing.T) {
+	t.Parallel()
+
+	oldCatalog := Catalog{Actions: []Action{
+		{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+		{ID: "invoice.delete-contact", Method: "DELETE", Path: "/api/invoicing/v1/contacts/{contactId}"},
+		{
+			ID:     "invoice.create-document",
+			Method: "POST",
+			Path:   "/api/invoicing/v1/documents/{docType}",
+			Parameters: []ActionParameter{
+				{Name: "docType", In: "path", Required: true, Type: "string", Enum: []string{"invoice", "salesreceipt"}},
+			},
+			RequestBody: &ActionRequestBody{Fields: []ActionBodyField{
+				{Name: "contactId", Type: "string"},
+				{Name: "items", Type: "array", Item: &ActionBodyItem{Type: "object", Fields: []ActionBodyField{
+					{Name: "units", Type: "number"},
+				}}},
+			}},
+		},
+	}}
+	newCatalog := Catalog{Actions: []Action{
+		{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+		{ID: "invoice.list-products", Method: "GET", Path: "/api/invoicing/v1/products"},
+		{
+			ID:     "invoice.create-document",
+			Method: "POST",
+			Path:   "/api/invoicing/v1/documents/{docType}",
+			Parameters: []ActionParameter{
+				{Name: "docType", In: "path", Required: true, Type: "string", Enum: []string{"invoice", "salesreceipt", "proform"}},
+				{Name: "lang", In: "query", Type: "string"},
+			},
+			RequestBody: &ActionRequestBody{Fields: []ActionBodyField{
+				{Name: "contactId", Type: "string", Required: true},
+				{Name: "items", Type: "array", Item: &ActionBodyItem{Type: "object", Fields: []ActionBodyField{
+					{Name: "units", Type: "integer"},
+				}}},
+			}},
+		},
+	}}
+
+	diff := DiffCatalogs(oldCatalog, newCatalog)
+
+	if len(diff.Added) != 1 || diff.Added[0].ID != "invoice.list-products" {
+		t.Fatalf("added = %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != "invoice.delete-contact" {
+		t.Fatalf("removed = %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].ID != "invoice.create-document" {
+		t.Fatalf("changed = %+v", diff.Changed)
+	}
+
+	byTarget := make(map[string]ActionChange)
+	for _, change := range diff.Changed[0].Changes {
+		byTarget[change.Kind+" "+change.Target] = change
+	}
+
+	if change, ok := byTarget["parameter_enum path:docType"]; !ok || change.Breaking {
+		t.Fatalf("expected non-breaking enum extension, got %+v", change)
+	}
+	if change, ok := byTarget["parameter_added query:lang"]; !ok || change.Breaking {
+		t.Fatalf("expected non-breaking optional parameter, got %+v", change)
+	}
+	if change, ok := byTarget["body_field_required $.contactId"]; !ok || !change.Breaking {
+		t.Fatalf("expected breaking required field, got %+v", change)
+	}
+	if change, ok := byTarget["body_field_type $.items[].units"]; !ok || !change.Breaking || change.Old != "number" || change.New != "integer" {
+		t.Fatalf("expected breaking nested type change, got %+v", change)
+	}
+
+	// Removed action + required field + nested type change.
+	if diff.Breaking != 3 {
+		t.Fatalf("breaking = %d, want 3", diff.Breaking)
+	}
+}
+
+func TestDiffCatalogsIdentical(t *testing.T) {
+	t.Parallel()
+
+	catalog := Catalog{Actions: []Action{{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"}}}
+	if diff := DiffCatalogs(catalog, catalog); !diff.Empty() || diff.Breaking != 0 {
+		t.Fatalf("expected empty diff, got %+v", diff)
+	}
+}
+
+func TestDiffCatalogsTypeChangeAndEnumOrder(t *testing.T) {
+	t.Parallel()
+
+	action := func(items ActionBodyField, status []string) Catalog {
+		return Catalog{Actions: []Action{{
+			ID:         "invoice.create-document",
+			Method:     "POST",
+			Path:       "/api/invoicing/v1/documents/{docType}",
+			Parameters: []ActionParameter{{Name: "status", In: "query", Type: "string", Enum: status}},
+			RequestBody: &ActionRequestBody{Fields: []ActionBodyField{
+				items,
+				{Name: "currency", Type: "string", Enum: status},
+			}},
+		}}}
+	}
+	oldCatalog := action(ActionBodyField{Name: "items", Type: "array", Item: &ActionBodyItem{Type: "object", Fields: []ActionBodyField{
+		{Name: "units", Type: "number"},
+	}}}, []string{"draft", "paid"})
+	newCatalog := action(ActionBodyField{Name: "items", Type: "string"}, []string{"paid", "draft"})
+
+	diff := DiffCatalogs(oldCatalog, newCatalog)
+	if len(diff.Changed) != 1 {
+		t.Fatalf("changed = %+v", diff.Changed)
+	}
+	changes := diff.Changed[0].Changes
+	// Only the array -> string change; no item changes and no reordered-enum changes.
+	if len(changes) != 1 || changes[0].Kind != ChangeBodyFieldType || changes[0].Target != "$.items" || diff.Breaking != 1 {
+		t.Fatalf("changes = %+v, breaking = %d", changes, diff.Breaking)
+	}
+}
+
+func TestDiffCatalogsWithoutMetadata(t *testing.T) {
+	t.Parallel()
+
+	embedded := Catalog{Actions: []Action{
+		{ID: "invoice.create-contact", Method: "POST", Path: "/api/invoicing/v1/contacts", NoMetadata: true},
+		{ID: "invoice.delete-contact", Method: "DELETE", Path: "/api/invoicing/v1/contacts/{contactId}", NoMetadata: true},
+	}}
+	imported := Catalog{Actions: []Action{
+		{
+			ID:          "invoice.create-contact",
+			Method:      "POST",
+			Path:        "/api/invoicing/v1/contacts",
+			Parameters:  []ActionParameter{{Name: "lang", In: "query", Required: true, Type: "string"}},
+			RequestBody: &ActionRequestBody{Fields: []ActionBodyField{{Name: "name", Type: "string", Required: true}}},
+		},
+		{ID: "invoice.delete-contact", Method: "DELETE", Path: "/api/invoicing/v2/contacts/{contactId}"},
+	}}
+
+	// Only the path change is reported: parameters and fields are unknown on one side.
+	diff := DiffCatalogs(embedded, imported)
+	if len(diff.Changed) != 1 || diff.Changed[0].ID != "invoice.delete-contact" || diff.Breaking != 1 {
+		t.Fatalf("diff = %+v", diff)
+	}
+	if reverse := DiffCatalogs(imported, embedded); len(reverse.Changed) != 1 || reverse.Breaking != 1 {
+		t.Fatalf("reverse diff = %+v", reverse)
+	}
+}
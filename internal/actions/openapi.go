@@ -0,0 +1,191 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceOpenAPI prefixes the source of a catalog imported from an OpenAPI document.
+const SourceOpenAPI = "openapi"
+
+const maxRefDepth = 32
+
+// LoadCatalogFromOpenAPI builds an action catalog from a local OpenAPI 3 document (JSON or YAML).
+func LoadCatalogFromOpenAPI(path string) (Catalog, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("reading OpenAPI document: %w", err)
+	}
+
+	actions, err := buildActionsFromOpenAPI(b)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("parsing OpenAPI document %s: %w", path, err)
+	}
+	if len(actions) == 0 {
+		return Catalog{}, fmt.Errorf("OpenAPI document %s has no operations", path)
+	}
+
+	actionsByKey := make(map[string]Action, len(actions))
+	for _, action := range actions {
+		actionsByKey[action.Method+" "+action.Path] = action
+	}
+
+	return Catalog{
+		GeneratedAt: time.Now().UTC(),
+		Source:      SourceOpenAPI + ":" + path,
+		Actions:     sortedActions(actionsByKey),
+	}, nil
+}
+
+// MergeCatalogs returns base with every action of overlay added. An overlay action
+// replaces the base actions with the same method and path or the same ID, so an
+// imported action keeps its ID even when the base has it at another path. The
+// source records both catalogs.
+func MergeCatalogs(base, overlay Catalog) Catalog {
+	overlayIDs := make(map[string]bool, len(overlay.Actions))
+	for _, action := range overlay.Actions {
+		overlayIDs[action.ID] = true
+	}
+
+	actionsByKey := make(map[string]Action, len(base.Actions)+len(overlay.Actions))
+	for _, action := range base.Actions {
+		if !overlayIDs[action.ID] {
+			actionsByKey[action.Method+" "+action.Path] = action
+		}
+	}
+	for _, action := range overlay.Actions {
+		actionsByKey[action.Method+" "+action.Path] = action
+	}
+
+	source := overlay.Source
+	if base.Source != "" {
+		source = base.Source + " + " + overlay.Source
+	}
+	return Catalog{
+		GeneratedAt: overlay.GeneratedAt,
+		Source:      source,
+		Actions:     sortedActions(actionsByKey),
+	}
+}
+
+func buildActionsFromOpenAPI(data []byte) ([]Action, error) {
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	document = normalizeYAMLValue(document)
+	root, ok := document.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("document must be an object")
+	}
+
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(strings.TrimSpace(version), "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, expected 3.x", version)
+	}
+
+	resolved, err := resolveLocalRefs(root, root, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema schemaSpec
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+
+	return buildActionsFromSchema(schema)
+}
+
+// normalizeYAMLValue converts YAML maps with non-string keys (e.g. response codes) into JSON-compatible maps.
+func normalizeYAMLValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeYAMLValue(item)
+		}
+		return v
+	case map[any]any:
+		converted := make(map[string]any, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = normalizeYAMLValue(item)
+		}
+		return converted
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeYAMLValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// resolveLocalRefs inlines "#/..." references so components are visible to the schema decoder.
+func resolveLocalRefs(value any, root map[string]any, depth int) (any, error) {
+	if depth > maxRefDepth {
+		// Recursive schemas are truncated instead of expanded forever.
+		return map[string]any{}, nil
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, "#/") {
+			target, err := lookupPointer(root, ref)
+			if err != nil {
+				return nil, err
+			}
+			return resolveLocalRefs(target, root, depth+1)
+		}
+
+		resolved := make(map[string]any, len(v))
+		for key, item := range v {
+			r, err := resolveLocalRefs(item, root, depth)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			r, err := resolveLocalRefs(item, root, depth)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	default:
+		return v, nil
+	}
+}
+
+func lookupPointer(root map[string]any, ref string) (any, error) {
+	var current any = root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %s", ref)
+		}
+		current, ok = obj[token]
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %s", ref)
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,141 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testOpenAPIYAML = `openapi: 3.0.0
+info:
+  title: Invoice API
+servers:
+  - url: https://api.holded.com/api/invoicing/v1
+paths:
+  /contacts:
+    get:
+      operationId: List Contacts
+      responses:
+        200:
+          description: OK
+    post:
+      operationId: Create Contact
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Contact'
+  /contacts/{contactId}:
+    parameters:
+      - $ref: '#/components/parameters/contactId'
+    get:
+      operationId: Get Contact
+components:
+  parameters:
+    contactId:
+      name: contactId
+      in: path
+      required: true
+      schema:
+        type: string
+  schemas:
+    Contact:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        type:
+          type: string
+          enum: [client, supplier]
+`
+
+func TestLoadCatalogFromOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(specPath, []byte(testOpenAPIYAML), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	catalog, err := LoadCatalogFromOpenAPI(specPath)
+	if err != nil {
+		t.Fatalf("LoadCatalogFromOpenAPI() error = %v", err)
+	}
+	if catalog.Source != SourceOpenAPI+":"+specPath {
+		t.Fatalf("source = %q", catalog.Source)
+	}
+	if len(catalog.Actions) != 3 {
+		t.Fatalf("len(actions) = %d, want 3", len(catalog.Actions))
+	}
+
+	create, err := catalog.Find("invoice.create-contact")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if create.Path != "/api/invoicing/v1/contacts" {
+		t.Fatalf("path = %s", create.Path)
+	}
+	if create.RequestBody == nil || len(create.RequestBody.Fields) != 2 {
+		t.Fatalf("expected referenced body schema to be expanded, got %+v", create.RequestBody)
+	}
+
+	get, err := catalog.Find("Get Contact")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "contactId" {
+		t.Fatalf("expected referenced path parameter, got %+v", get.Parameters)
+	}
+}
+
+func TestLoadCatalogFromOpenAPIRejectsSwagger2(t *testing.T) {
+	t.Parallel()
+
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(`{"swagger":"2.0","info":{"title":"Invoice API"},"paths":{}}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := LoadCatalogFromOpenAPI(specPath); err == nil {
+		t.Fatalf("expected error for swagger 2.0 document")
+	}
+}
+
+func TestMergeCatalogs(t *testing.T) {
+	t.Parallel()
+
+	base := Catalog{Source: "embedded:https://developers.holded.com", Actions: []Action{
+		{ID: "invoice.list-contacts", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+		{ID: "invoice.list-products", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/products"},
+		{ID: "invoice.get-product", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/products/{productId}"},
+	}}
+	overlay := Catalog{Source: "openapi:spec.yaml", Actions: []Action{
+		{ID: "invoice.list-contacts", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/contacts", Summary: "patched"},
+		{ID: "invoice.get-product", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v2/products/{productId}"},
+	}}
+
+	merged := MergeCatalogs(base, overlay)
+	if len(merged.Actions) != 3 {
+		t.Fatalf("len(actions) = %d, want 3", len(merged.Actions))
+	}
+	// An imported action with a built-in ID replaces it instead of being renamed.
+	product, err := merged.Find("invoice.get-product")
+	if err != nil || product.Path != "/api/invoicing/v2/products/{productId}" {
+		t.Fatalf("Find(invoice.get-product) = %+v, %v", product, err)
+	}
+	if _, err := merged.Find("invoice.get-product-2"); err == nil {
+		t.Fatalf("imported action was renamed")
+	}
+	action, err := merged.Find("invoice.list-contacts")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if action.Summary != "patched" {
+		t.Fatalf("expected overlay action to win, got %+v", action)
+	}
+	if merged.Source != "embedded:https://developers.holded.com + openapi:spec.yaml" {
+		t.Fatalf("source = %q", merged.Source)
+	}
+}
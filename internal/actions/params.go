@@ -0,0 +1,176 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var pathPlaceholderPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// ValidateRequestParameters validates --path and --query values against action metadata.
+// Issue fields are "<in>:<name>", e.g. query:page or path:docType. Missing path values are
+// left to ResolvePathTemplate. Query keys are only checked when the action declares parameters,
+// since catalogs without parameter metadata (like the embedded snapshot) cannot tell them apart.
+func ValidateRequestParameters(action Action, pathParams map[string]string, query url.Values) []ValidationIssue {
+	placeholders := make(map[string]bool)
+	for _, m := range pathPlaceholderPattern.FindAllStringSubmatch(action.Path, -1) {
+		placeholders[m[1]] = true
+	}
+
+	declared := make(map[string]ActionParameter, len(action.Parameters))
+	for _, parameter := range action.Parameters {
+		declared[parameter.In+":"+parameter.Name] = parameter
+	}
+
+	issues := make([]ValidationIssue, 0)
+	for name, value := range pathParams {
+		if !placeholders[name] {
+			issues = append(issues, ValidationIssue{
+				Field:   "path:" + name,
+				Message: "unknown path parameter",
+			})
+			continue
+		}
+		if parameter, ok := declared["path:"+name]; ok {
+			issues = append(issues, validateParameterValues(parameter, []string{value})...)
+		}
+	}
+
+	if len(action.Parameters) > 0 {
+		for name, values := range query {
+			parameter, ok := declared["query:"+name]
+			if !ok {
+				issues = append(issues, ValidationIssue{
+					Field:   "query:" + name,
+					Message: "unknown query parameter",
+				})
+				continue
+			}
+			issues = append(issues, validateParameterValues(parameter, values)...)
+		}
+
+		for _, parameter := range action.Parameters {
+			if parameter.In == "query" && parameter.Required && len(query[parameter.Name]) == 0 {
+				issues = append(issues, ValidationIssue{
+					Field:   "query:" + parameter.Name,
+					Message: "required query parameter is missing",
+				})
+			}
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Field != issues[j].Field {
+			return issues[i].Field < issues[j].Field
+		}
+		return issues[i].Message < issues[j].Message
+	})
+
+	return issues
+}
+
+func validateParameterValues(parameter ActionParameter, values []string) []ValidationIssue {
+	field := parameter.In + ":" + parameter.Name
+	kind := parameter.Type
+	if strings.HasPrefix(kind, "array[") {
+		kind = strings.TrimSuffix(strings.TrimPrefix(kind, "array["), "]")
+	} else if kind != "array" && len(values) > 1 {
+		return []ValidationIssue{{
+			Field:   field,
+			Message: "parameter does not accept multiple values",
+		}}
+	}
+
+	var issues []ValidationIssue
+	for _, value := range values {
+		if !matchesParameterType(value, kind) {
+			issues = append(issues, ValidationIssue{
+				Field:   field,
+				Message: fmt.Sprintf("expected %s, got %q", kind, value),
+			})
+			continue
+		}
+
+		if len(parameter.Enum) > 0 && !containsString(parameter.Enum, value) {
+			issues = append(issues, ValidationIssue{
+				Field:   field,
+				Message: fmt.Sprintf("value must be one of: %s", strings.Join(parameter.Enum, ", ")),
+			})
+		}
+	}
+
+	return issues
+}
+
+func matchesParameterType(value, expected string) bool {
+	switch expected {
+	case "integer":
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case "number":
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case "boolean":
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+func containsString(values []string, needle string) bool {
+	for _, value := range values {
+		if value == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// Paginated reports whether the action is a GET list endpoint that pages its results,
+// either because it declares a page query parameter or because its id names a list operation.
+func (a Action) Paginated() bool {
+	if a.Method != http.MethodGet {
+		return false
+	}
+
+	for _, parameter := range a.Parameters {
+		if parameter.In == "query" && parameter.Name == "page" {
+			return true
+		}
+	}
+
+	_, name, _ := strings.Cut(a.ID, ".")
+	return strings.HasPrefix(name, "list")
+}
+
+// AcceptsQueryParameter reports whether the action declares the query parameter name.
+// Actions without parameter metadata accept any name.
+func (a Action) AcceptsQueryParameter(name string) bool {
+	if len(a.Parameters) == 0 {
+		return true
+	}
+	for _, parameter := range a.Parameters {
+		if parameter.In == "query" && parameter.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// destructiveActionIDs lists non-DELETE actions with effects that cannot be undone.
+var destructiveActionIDs = map[string]bool{
+	"invoice.pay-document":  true,
+	"team.employeeclockout": true,
+}
+
+// IsDestructive reports whether running the action needs confirmation: DELETE actions,
+// actions flagged destructive in the catalog and known irreversible operations.
+func (a Action) IsDestructive() bool {
+	return a.Method == http.MethodDelete || a.Destructive || destructiveActionIDs[a.ID]
+}
@@ -0,0 +1,120 @@
+package actions
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestValidateRequestParameters(t *testing.T) {
+	t.Parallel()
+
+	action := Action{
+		ID:   "invoice.list-documents",
+		Path: "/api/invoicing/v1/documents/{docType}",
+		Parameters: []ActionParameter{
+			{Name: "docType", In: "path", Required: true, Type: "string", Enum: []string{"invoice", "salesreceipt"}},
+			{Name: "page", In: "query", Type: "integer"},
+			{Name: "paid", In: "query", Type: "boolean"},
+			{Name: "sort", In: "query", Required: true, Type: "string"},
+		},
+	}
+
+	issues := ValidateRequestParameters(action, map[string]string{"docType": "invoice"}, url.Values{
+		"page": {"2"},
+		"paid": {"true"},
+		"sort": {"created-asc"},
+	})
+	if len(issues) != 0 {
+		t.Fatalf("expected no validation issues, got %+v", issues)
+	}
+
+	issues = ValidateRequestParameters(action, map[string]string{"docType": "bill", "contactId": "x"}, url.Values{
+		"page":  {"two"},
+		"paid":  {"yes"},
+		"limit": {"10"},
+	})
+	want := []ValidationIssue{
+		{Field: "path:contactId", Message: "unknown path parameter"},
+		{Field: "path:docType", Message: "value must be one of: invoice, salesreceipt"},
+		{Field: "query:limit", Message: "unknown query parameter"},
+		{Field: "query:page", Message: `expected integer, got "two"`},
+		{Field: "query:paid", Message: `expected boolean, got "yes"`},
+		{Field: "query:sort", Message: "required query parameter is missing"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("issues = %+v, want %+v", issues, want)
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Fatalf("issues[%d] = %+v, want %+v", i, issues[i], want[i])
+		}
+	}
+}
+
+func TestValidateRequestParametersWithoutMetadata(t *testing.T) {
+	t.Parallel()
+
+	action := Action{ID: "invoice.list-contacts", Path: "/api/invoicing/v1/contacts"}
+	if issues := ValidateRequestParameters(action, nil, url.Values{"customId": {"ref"}}); len(issues) != 0 {
+		t.Fatalf("expected query keys to pass without metadata, got %+v", issues)
+	}
+}
+
+func TestActionPaginated(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		action Action
+		want   bool
+	}{
+		{action: Action{ID: "invoice.list-contacts", Method: "GET"}, want: true},
+		{action: Action{ID: "crm.list-leads", Method: "GET"}, want: true},
+		{action: Action{ID: "invoice.get-contact", Method: "GET"}, want: false},
+		{action: Action{ID: "invoice.search", Method: "GET", Parameters: []ActionParameter{{Name: "page", In: "query"}}}, want: true},
+		{action: Action{ID: "invoice.list-contacts", Method: "POST"}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.action.Paginated(); got != tt.want {
+			t.Fatalf("%s %s Paginated() = %v, want %v", tt.action.Method, tt.action.ID, got, tt.want)
+		}
+	}
+}
+
+func TestActionAcceptsQueryParameter(t *testing.T) {
+	t.Parallel()
+
+	withLimit := Action{Parameters: []ActionParameter{{Name: "page", In: "query"}, {Name: "limit", In: "query"}}}
+	withoutLimit := Action{Parameters: []ActionParameter{{Name: "page", In: "query"}, {Name: "limit", In: "path"}}}
+
+	if !withLimit.AcceptsQueryParameter("limit") {
+		t.Fatalf("expected declared limit to be accepted")
+	}
+	if withoutLimit.AcceptsQueryParameter("limit") {
+		t.Fatalf("expected undeclared query limit to be rejected")
+	}
+	if !(Action{}).AcceptsQueryParameter("limit") {
+		t.Fatalf("expected actions without metadata to accept any parameter")
+	}
+}
+
+func TestActionIsDestructive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		action Action
+		want   bool
+	}{
+		{action: Action{ID: "invoice.delete-contact", Method: "DELETE"}, want: true},
+		{action: Action{ID: "invoice.pay-document", Method: "POST"}, want: true},
+		{action: Action{ID: "team.employeeclockout", Method: "POST"}, want: true},
+		{action: Action{ID: "invoice.create-contact", Method: "POST", Destructive: true}, want: true},
+		{action: Action{ID: "invoice.create-contact", Method: "POST"}, want: false},
+		{action: Action{ID: "invoice.list-contacts", Method: "GET"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.action.IsDestructive(); got != tt.want {
+			t.Fatalf("%s IsDestructive() = %v, want %v", tt.action.ID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+)
+
+var (
+	ErrReadOnly         = errors.New("read-only mode allows only GET actions")
+	ErrActionNotAllowed = errors.New("action not allowed")
+)
+
+// Policy restricts which actions may run. Allow and Deny hold action id glob
+// patterns such as invoice.list-*; Deny wins over Allow, and an empty Allow permits
+// every action that is not denied.
+type Policy struct {
+	ReadOnly bool
+	Allow    []string
+	Deny     []string
+}
+
+// Check returns an error wrapping ErrReadOnly or ErrActionNotAllowed when the policy forbids the action.
+func (p Policy) Check(action Action) error {
+	if p.ReadOnly && action.Method != http.MethodGet {
+		return fmt.Errorf("%w: %s is %s", ErrReadOnly, action.ID, action.Method)
+	}
+
+	denied, pattern, err := matchAny(action.ID, p.Deny)
+	if err != nil {
+		return err
+	}
+	if denied {
+		return fmt.Errorf("%w: %s matches deny pattern %q", ErrActionNotAllowed, action.ID, pattern)
+	}
+
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	allowed, _, err := matchAny(action.ID, p.Allow)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s matches no allow pattern", ErrActionNotAllowed, action.ID)
+	}
+	return nil
+}
+
+func matchAny(id string, patterns []string) (bool, string, error) {
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, id)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid action pattern %q: %w", pattern, err)
+		}
+		if matched {
+			return true, pattern, nil
+		}
+	}
+	return false, "", nil
+}
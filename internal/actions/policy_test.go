@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	t.Parallel()
+
+	list := Action{ID: "invoice.list-contacts", Method: "GET"}
+	get := Action{ID: "invoice.get-contact", Method: "GET"}
+	listLeads := Action{ID: "crm.list-leads", Method: "GET"}
+	create := Action{ID: "invoice.create-contact", Method: "POST"}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		action Action
+		want   error
+	}{
+		{name: "no policy", action: create},
+		{name: "read-only allows GET", policy: Policy{ReadOnly: true}, action: list},
+		{name: "read-only refuses POST", policy: Policy{ReadOnly: true}, action: create, want: ErrReadOnly},
+		{name: "allow glob", policy: Policy{Allow: []string{"invoice.list-*"}}, action: list},
+		{name: "outside allow list", policy: Policy{Allow: []string{"invoice.list-*"}}, action: get, want: ErrActionNotAllowed},
+		{name: "deny wins", policy: Policy{Allow: []string{"*.list-*"}, Deny: []string{"crm.*"}}, action: listLeads, want: ErrActionNotAllowed},
+		{name: "deny only", policy: Policy{Deny: []string{"crm.*"}}, action: list},
+	}
+	for _, tt := range tests {
+		err := tt.policy.Check(tt.action)
+		if tt.want == nil && err != nil {
+			t.Fatalf("%s: Check() error = %v", tt.name, err)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Fatalf("%s: Check() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if err := (Policy{Deny: []string{"["}}).Check(list); err == nil {
+		t.Fatalf("expected error for invalid pattern")
+	}
+}
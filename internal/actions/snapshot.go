@@ -0,0 +1,102 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jaumecornado/holdedcli/docs"
+)
+
+const (
+	// SourceEmbedded prefixes the source of the catalog bundled in the binary.
+	SourceEmbedded = "embedded"
+	// SourceCache prefixes the source of a catalog loaded from the local cache.
+	SourceCache = "cache"
+)
+
+// EmbeddedCatalog returns the docs/actions.json snapshot bundled in the binary.
+func EmbeddedCatalog() (Catalog, error) {
+	catalog, err := decodeCatalog(docs.ActionsJSON)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("decoding embedded catalog: %w", err)
+	}
+
+	// The snapshot only records ids and endpoints; mark those actions so callers skip
+	// (and warn about) validation instead of rejecting every body as unexpected.
+	for i := range catalog.Actions {
+		if len(catalog.Actions[i].Parameters) == 0 && catalog.Actions[i].RequestBody == nil {
+			catalog.Actions[i].NoMetadata = true
+		}
+	}
+
+	catalog.Source = SourceEmbedded + ":" + catalog.Source
+	return catalog, nil
+}
+
+// LoadCatalogFile reads a catalog JSON file such as docs/actions.json or a cache written by SaveCatalog.
+func LoadCatalogFile(path string) (Catalog, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	catalog, err := decodeCatalog(b)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("decoding catalog %s: %w", path, err)
+	}
+	return catalog, nil
+}
+
+// LoadCachedCatalog reads a catalog written by SaveCatalog.
+func LoadCachedCatalog(path string) (Catalog, error) {
+	catalog, err := LoadCatalogFile(path)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	catalog.Source = SourceCache + ":" + path
+	return catalog, nil
+}
+
+// LoadLocalCatalog returns the cached catalog when present and the embedded snapshot otherwise.
+func LoadLocalCatalog(cachePath string) (Catalog, error) {
+	if cachePath != "" {
+		catalog, err := LoadCachedCatalog(cachePath)
+		if err == nil {
+			return catalog, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return Catalog{}, err
+		}
+	}
+
+	return EmbeddedCatalog()
+}
+
+// SaveCatalog writes a catalog to path as indented JSON.
+func SaveCatalog(path string, catalog Catalog) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+func decodeCatalog(b []byte) (Catalog, error) {
+	var catalog Catalog
+	if err := json.Unmarshal(b, &catalog); err != nil {
+		return Catalog{}, err
+	}
+	if len(catalog.Actions) == 0 {
+		return Catalog{}, fmt.Errorf("catalog has no actions")
+	}
+	return catalog, nil
+}
@@ -0,0 +1,71 @@
+package actions
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmbeddedCatalog(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := EmbeddedCatalog()
+	if err != nil {
+		t.Fatalf("EmbeddedCatalog() error = %v", err)
+	}
+	if !strings.HasPrefix(catalog.Source, SourceEmbedded+":") {
+		t.Fatalf("source = %q, want embedded prefix", catalog.Source)
+	}
+	if catalog.GeneratedAt.IsZero() {
+		t.Fatalf("expected generated_at in embedded catalog")
+	}
+
+	action, err := catalog.Find("invoice.create-contact")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if !action.NoMetadata {
+		t.Fatalf("expected embedded action without metadata to be marked NoMetadata")
+	}
+	if issues := ValidateBodyParameters(action, []byte(`{"name":"Acme"}`)); len(issues) != 0 {
+		t.Fatalf("expected no validation issues, got %+v", issues)
+	}
+}
+
+func TestLoadLocalCatalogPrefersCache(t *testing.T) {
+	t.Parallel()
+
+	cachePath := filepath.Join(t.TempDir(), "actions.json")
+
+	catalog, err := LoadLocalCatalog(cachePath)
+	if err != nil {
+		t.Fatalf("LoadLocalCatalog() error = %v", err)
+	}
+	if !strings.HasPrefix(catalog.Source, SourceEmbedded+":") {
+		t.Fatalf("source = %q, want embedded fallback", catalog.Source)
+	}
+
+	generatedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	if err := SaveCatalog(cachePath, Catalog{
+		GeneratedAt: generatedAt,
+		Source:      "https://developers.holded.com/reference/api-key",
+		Actions:     []Action{{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"}},
+	}); err != nil {
+		t.Fatalf("SaveCatalog() error = %v", err)
+	}
+
+	catalog, err = LoadLocalCatalog(cachePath)
+	if err != nil {
+		t.Fatalf("LoadLocalCatalog() error = %v", err)
+	}
+	if catalog.Source != SourceCache+":"+cachePath {
+		t.Fatalf("source = %q", catalog.Source)
+	}
+	if !catalog.GeneratedAt.Equal(generatedAt) {
+		t.Fatalf("generated_at = %s, want %s", catalog.GeneratedAt, generatedAt)
+	}
+	if len(catalog.Actions) != 1 {
+		t.Fatalf("len(actions) = %d, want 1", len(catalog.Actions))
+	}
+}
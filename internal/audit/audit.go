@@ -0,0 +1,113 @@
+// Package audit records executed actions in an append-only JSONL file.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Entry is one executed action. The request body is stored only as a SHA-256 hash and size.
+type Entry struct {
+	Time             time.Time           `json:"time"`
+	User             string              `json:"user,omitempty"`
+	Host             string              `json:"host,omitempty"`
+	Profile          string              `json:"profile,omitempty"`
+	CredentialSource string              `json:"credential_source"`
+	ActionID         string              `json:"action_id"`
+	Method           string              `json:"method"`
+	Path             string              `json:"path"`
+	Query            map[string][]string `json:"query,omitempty"`
+	BodySHA256       string              `json:"body_sha256,omitempty"`
+	BodySize         int                 `json:"body_size,omitempty"`
+	StatusCode       int                 `json:"status_code,omitempty"`
+	DurationMS       int64               `json:"duration_ms"`
+	ErrorCode        string              `json:"error_code,omitempty"`
+}
+
+// Filter selects entries in Read. Action is a glob pattern such as invoice.delete-*.
+type Filter struct {
+	Since  time.Time
+	Action string
+}
+
+// HashBody returns the hex SHA-256 of body, or "" for an empty body.
+func HashBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Append writes entry as one line at the end of the log, creating it (0600) if needed.
+func Append(logPath string, entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Read returns the entries matching filter in log order. A missing log has no entries.
+func Read(logPath string, filter Filter) ([]Entry, error) {
+	if filter.Action != "" {
+		if _, err := path.Match(filter.Action, ""); err != nil {
+			return nil, fmt.Errorf("invalid action pattern %q: %w", filter.Action, err)
+		}
+	}
+
+	entries := make([]Entry, 0)
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", logPath, lineNo, err)
+		}
+		if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+			continue
+		}
+		if filter.Action != "" {
+			if matched, _ := path.Match(filter.Action, entry.ActionID); !matched {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	t.Parallel()
+
+	logPath := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: base, ActionID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts", StatusCode: 200},
+		{Time: base.Add(time.Hour), ActionID: "invoice.delete-contact", Method: "DELETE", Path: "/api/invoicing/v1/contacts/c1", StatusCode: 200},
+		{Time: base.Add(2 * time.Hour), ActionID: "invoice.delete-document", Method: "DELETE", ErrorCode: "API_ERROR", BodySHA256: HashBody([]byte(`{}`))},
+	}
+	for _, entry := range entries {
+		if err := Append(logPath, entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	raw, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("reading log: %v", err)
+	}
+	if lines := strings.Count(string(raw), "\n"); lines != 3 {
+		t.Fatalf("log has %d lines, want 3", lines)
+	}
+
+	all, err := Read(logPath, Filter{})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("Read() = %d entries, %v", len(all), err)
+	}
+
+	deletes, err := Read(logPath, Filter{Action: "invoice.delete-*", Since: base.Add(90 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(deletes) != 1 || deletes[0].ActionID != "invoice.delete-document" || deletes[0].ErrorCode != "API_ERROR" {
+		t.Fatalf("filtered entries = %+v", deletes)
+	}
+
+	missing, err := Read(filepath.Join(t.TempDir(), "none.jsonl"), Filter{})
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("Read(missing) = %v, %v", missing, err)
+	}
+
+	if _, err := Read(logPath, Filter{Action: "["}); err == nil {
+		t.Fatalf("expected error for invalid action pattern")
+	}
+}
+
+func TestHashBody(t *testing.T) {
+	t.Parallel()
+
+	if HashBody(nil) != "" {
+		t.Fatalf("empty body should have no hash")
+	}
+	if got := HashBody([]byte("abc")); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatalf("HashBody() = %s", got)
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -9,32 +10,68 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"os/user"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jaumecornado/holdedcli/internal/actions"
+	"github.com/jaumecornado/holdedcli/internal/audit"
 	"github.com/jaumecornado/holdedcli/internal/config"
 	"github.com/jaumecornado/holdedcli/internal/holded"
+	"github.com/jaumecornado/holdedcli/internal/humanize"
+	"github.com/jaumecornado/holdedcli/internal/jq"
+	"github.com/jaumecornado/holdedcli/internal/mock"
 )
 
-const outputVersion = "v1"
+const (
+	outputVersion = "v1"
+	outputNDJSON  = "ndjson"
+	redactedValue = "<redacted>"
+)
 
 var usageText = strings.TrimSpace(`Usage:
-  holded auth set --api-key <key> [--json]
-  holded auth status [--json]
-  holded ping [--api-key <key>] [--base-url <url>] [--path <path>] [--timeout 10s] [--json]
-  holded actions list [--filter <text>] [--timeout 15s] [--json]
+  holded auth set --api-key <key> [--profile <name>] [--encrypt] [--json]
+  holded auth status [--profile <name>] [--verify [--base-url <url>] [--timeout 10s]] [--json]
+  holded auth list [--json]
+  holded auth use <profile> [--json]
+  holded ping [--api-key <key>] [--base-url <url>] [--path <path>] [--timeout 10s] [--retries 2] [--retry-max-wait 30s] [--rate-limit <rps>] [--rate-burst <n>] [--rate-limit-shared] [--json]
+  holded actions list [--filter <text>] [--timeout 15s] [--output table|csv|tsv|yaml|json] [--columns <paths>] [--template '<tmpl>'|--template-file <file>] [--json]
   holded actions describe <action-id|operation-id> [--timeout 15s] [--json]
-  holded actions run <action-id|operation-id> [--api-key <key>] [--base-url <url>] [--path key=value]... [--query key=value]... [--body '<json>'] [--body-file file.json] [--file /path/to/file] [--skip-validation] [--timeout 30s] [--json]
+  holded actions refresh [--timeout 60s] [--json]
+  holded actions import --openapi <spec.json|spec.yaml> [--replace] [--json]
+  holded actions diff <old.json> <new.json> [--fail-on-breaking] [--json]
+  holded actions run <action-id|operation-id> [--api-key <key>] [--base-url <url>] [--path key=value]... [--query key=value]... [--body '<json>'] [--body-file file.json] [--file /path/to/file] [--skip-validation] [--timeout 30s] [--all [--max-pages <n>] [--page-size <n>]] [--output ndjson|table|csv|tsv|yaml|json] [--columns <paths>] [--query-output '<expr>'] [--template '<tmpl>'|--template-file <file>] [--humanize [--locale <lang>]] [--timezone <tz>] [--output-file <path>] [--dry-run] [--emit curl|httpie|http-file] [--yes] [--retries 2] [--retry-max-wait 30s] [--retry-non-idempotent] [--rate-limit <rps>] [--rate-burst <n>] [--rate-limit-shared] [--json]
+  holded audit list [--since 24h|2006-01-02|<RFC3339>] [--action <glob>] [--json]
+  holded mock serve [--port 8080] [--host 127.0.0.1] [--api-key <key>] [--timeout 15s] [--json]
   holded help
 
+Global flags:
+  --json              Machine-readable output
+  --profile <name>    Use a named profile from config.yaml (or HOLDED_PROFILE)
+  --output <format>   table, csv, tsv, yaml or json rendering of actions run/list results (actions run also takes ndjson)
+  --columns <paths>   Comma-separated columns for --output, dotted for nested fields (customer.name, items.0.price)
+  --verbose, --trace  Log each HTTP attempt (masked headers, timings, full error body) to stderr
+
 Credential priority:
-  --api-key > HOLDED_API_KEY > ~/.config/holdedcli/config.yaml`)
+  --api-key > HOLDED_API_KEY > api_key_command > encrypted credentials.enc > ~/.config/holdedcli/config.yaml (active profile)
+  HOLDED_API_KEY is ignored when --profile or HOLDED_PROFILE selects a profile
+
+Profile priority:
+  --profile > HOLDED_PROFILE > current_profile set by auth use > default
+
+Action catalog priority:
+  ~/.config/holdedcli/actions.json (written by actions refresh/import) > embedded snapshot`)
 
 type usageError struct {
 	message string
@@ -47,6 +84,7 @@ func (e *usageError) Error() string {
 type commandError struct {
 	code    string
 	message string
+	details any
 }
 
 func (e *commandError) Error() string {
@@ -54,33 +92,77 @@ func (e *commandError) Error() string {
 }
 
 type jsonResponse struct {
-	Version string     `json:"version"`
-	Success bool       `json:"success"`
-	Command string     `json:"command"`
-	Message string     `json:"message,omitempty"`
-	Data    any        `json:"data,omitempty"`
-	Error   *jsonError `json:"error,omitempty"`
+	Version string      `json:"version"`
+	Success bool        `json:"success"`
+	Command string      `json:"command"`
+	Message string      `json:"message,omitempty"`
+	Data    any         `json:"data,omitempty"`
+	Error   *jsonError  `json:"error,omitempty"`
+	Trace   []traceData `json:"trace,omitempty"`
 }
 
 type jsonError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
 }
 
 type authSetData struct {
-	ConfigPath string `json:"config_path"`
+	ConfigPath string   `json:"config_path"`
+	Profile    string   `json:"profile"`
+	Store      string   `json:"store"`
+	Migrated   []string `json:"migrated,omitempty"`
 }
 
 type authStatusData struct {
+	Configured   bool                  `json:"configured"`
+	Source       string                `json:"source"`
+	Profile      string                `json:"profile,omitempty"`
+	Fingerprint  string                `json:"fingerprint,omitempty"`
+	MaskedKey    string                `json:"masked_key,omitempty"`
+	Verification *authVerificationData `json:"verification,omitempty"`
+}
+
+type authVerificationData struct {
+	Status     string          `json:"status"`
+	BaseURL    string          `json:"base_url"`
+	StatusCode int             `json:"status_code,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	APIs       []apiAccessData `json:"apis,omitempty"`
+}
+
+type apiAccessData struct {
+	API        string `json:"api"`
+	Path       string `json:"path"`
+	Accessible bool   `json:"accessible"`
+	StatusCode int    `json:"status_code,omitempty"`
+}
+
+type authListData struct {
+	Current  string            `json:"current"`
+	Profiles []authProfileData `json:"profiles"`
+}
+
+type authProfileData struct {
+	Name       string `json:"name"`
 	Configured bool   `json:"configured"`
-	Source     string `json:"source"`
+	Source     string `json:"source,omitempty"`
+	BaseURL    string `json:"base_url,omitempty"`
+	Current    bool   `json:"current"`
+}
+
+type authUseData struct {
+	ConfigPath string `json:"config_path"`
+	Profile    string `json:"profile"`
 }
 
 type pingData struct {
 	BaseURL          string `json:"base_url"`
 	Path             string `json:"path"`
 	StatusCode       int    `json:"status_code"`
+	Attempts         int    `json:"attempts"`
 	CredentialSource string `json:"credential_source"`
+	Profile          string `json:"profile,omitempty"`
 }
 
 type actionSummary struct {
@@ -105,15 +187,98 @@ type actionsDescribeData struct {
 	Action      actions.Action `json:"action"`
 }
 
+type actionsRefreshData struct {
+	GeneratedAt string `json:"generated_at"`
+	Source      string `json:"source"`
+	Count       int    `json:"count"`
+	CachePath   string `json:"cache_path"`
+}
+
+type actionsImportData struct {
+	Source    string `json:"source"`
+	Imported  int    `json:"imported"`
+	Count     int    `json:"count"`
+	Replaced  bool   `json:"replaced"`
+	CachePath string `json:"cache_path"`
+}
+
+type actionsDiffData struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+	actions.CatalogDiff
+}
+
 type actionRunData struct {
-	ActionID         string `json:"action_id"`
-	API              string `json:"api"`
-	OperationID      string `json:"operation_id,omitempty"`
-	Method           string `json:"method"`
-	Path             string `json:"path"`
-	StatusCode       int    `json:"status_code"`
-	CredentialSource string `json:"credential_source"`
-	Response         any    `json:"response,omitempty"`
+	ActionID         string          `json:"action_id"`
+	API              string          `json:"api"`
+	OperationID      string          `json:"operation_id,omitempty"`
+	Method           string          `json:"method"`
+	Path             string          `json:"path"`
+	StatusCode       int             `json:"status_code"`
+	Attempts         int             `json:"attempts"`
+	CredentialSource string          `json:"credential_source"`
+	Profile          string          `json:"profile,omitempty"`
+	Pagination       *paginationData `json:"pagination,omitempty"`
+	OutputFile       *outputFileData `json:"output_file,omitempty"`
+	Response         any             `json:"response,omitempty"`
+}
+
+type dryRunData struct {
+	ActionID string            `json:"action_id"`
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers"`
+	BodySize int               `json:"body_size"`
+	Body     any               `json:"body,omitempty"`
+}
+
+type confirmationData struct {
+	ActionID string `json:"action_id"`
+	Method   string `json:"method"`
+	URL      string `json:"url"`
+}
+
+type emitData struct {
+	ActionID string `json:"action_id"`
+	Format   string `json:"format"`
+	Content  string `json:"content"`
+}
+
+type outputFileData struct {
+	Path        string `json:"path"`
+	Size        int    `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+type auditListData struct {
+	LogPath string        `json:"log_path"`
+	Count   int           `json:"count"`
+	Entries []audit.Entry `json:"entries"`
+}
+
+type mockServeData struct {
+	URL     string `json:"url"`
+	Actions int    `json:"actions"`
+	AnyKey  bool   `json:"any_key"`
+}
+
+type apiErrorData struct {
+	StatusCode  int              `json:"status_code"`
+	Status      string           `json:"status,omitempty"`
+	Message     string           `json:"message,omitempty"`
+	Info        string           `json:"info,omitempty"`
+	FieldErrors []fieldErrorData `json:"field_errors,omitempty"`
+	Body        string           `json:"body,omitempty"`
+}
+
+type fieldErrorData struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type paginationData struct {
+	Pages int `json:"pages"`
+	Items int `json:"items"`
 }
 
 type App struct {
@@ -125,11 +290,24 @@ type App struct {
 	saveConfig     func(path string, cfg config.Config) error
 	newClient      func(baseURL, apiKey string, httpClient *http.Client) (*holded.Client, error)
 	loadCatalog    func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error)
+	fetchCatalog   func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error)
+	saveCatalog    func(path string, catalog actions.Catalog) error
 	catalogHTTP    *http.Client
+	listen         func(network, address string) (net.Listener, error)
+	notifyContext  func() (context.Context, context.CancelFunc)
 	timeout        time.Duration
 	catalogTimeout time.Duration
+	refreshTimeout time.Duration
 	requestTimeout time.Duration
+	stdin          io.Reader
+	stdinLines     *bufio.Reader
+	interactive    func() bool
 	jsonOutput     bool
+	profile        string
+	trace          bool
+	traces         []traceData
+	output         string
+	columns        []string
 }
 
 func NewApp(out, errOut io.Writer) *App {
@@ -140,20 +318,28 @@ func NewApp(out, errOut io.Writer) *App {
 		errOut = io.Discard
 	}
 
-	return &App{
+	app := &App{
 		out:            out,
 		errOut:         errOut,
 		getenv:         os.Getenv,
+		stdin:          os.Stdin,
+		interactive:    stdinIsTerminal,
 		configPath:     config.DefaultPath,
 		loadConfig:     config.Load,
 		saveConfig:     config.Save,
 		newClient:      holded.NewClient,
-		loadCatalog:    actions.LoadCatalog,
+		fetchCatalog:   actions.FetchCatalog,
+		saveCatalog:    actions.SaveCatalog,
 		catalogHTTP:    &http.Client{Timeout: 20 * time.Second},
+		listen:         net.Listen,
+		notifyContext:  notifyInterrupt,
 		timeout:        10 * time.Second,
 		catalogTimeout: 15 * time.Second,
+		refreshTimeout: 60 * time.Second,
 		requestTimeout: 30 * time.Second,
 	}
+	app.loadCatalog = app.loadLocalCatalog
+	return app
 }
 
 func Run(args []string, out, errOut io.Writer) int {
@@ -161,11 +347,21 @@ func Run(args []string, out, errOut io.Writer) int {
 }
 
 func (a *App) Run(args []string) int {
-	remaining, jsonOutput := extractGlobalFlags(args)
-	a.jsonOutput = jsonOutput
+	remaining, globals, err := extractGlobalFlags(args)
+	a.jsonOutput = globals.json
+	a.profile = globals.profile
+	a.trace = globals.trace
+	a.output = globals.output
+	a.columns = parseColumns(globals.columns)
 	command := detectedCommand(remaining)
+	if err != nil {
+		return a.handleError(command, err)
+	}
+	if err := a.checkOutputFlags(command); err != nil {
+		return a.handleError(command, err)
+	}
 
-	err := a.execute(remaining)
+	err = a.execute(remaining)
 	if err == nil {
 		return 0
 	}
@@ -188,6 +384,10 @@ func (a *App) execute(args []string) error {
 		return a.handlePing(args[1:])
 	case "actions":
 		return a.handleActions(args[1:])
+	case "audit":
+		return a.handleAudit(args[1:])
+	case "mock":
+		return a.handleMock(args[1:])
 	default:
 		return &usageError{message: fmt.Sprintf("unknown command: %s", args[0])}
 	}
@@ -203,6 +403,10 @@ func (a *App) handleAuth(args []string) error {
 		return a.handleAuthSet(args[1:])
 	case "status":
 		return a.handleAuthStatus(args[1:])
+	case "list":
+		return a.handleAuthList(args[1:])
+	case "use":
+		return a.handleAuthUse(args[1:])
 	default:
 		return &usageError{message: fmt.Sprintf("unknown auth subcommand: %s", args[0])}
 	}
@@ -213,6 +417,7 @@ func (a *App) handleAuthSet(args []string) error {
 	fs.SetOutput(io.Discard)
 
 	apiKey := fs.String("api-key", "", "Holded API key")
+	encrypt := fs.Bool("encrypt", false, "Store API keys encrypted with a passphrase instead of in config.yaml")
 	if err := fs.Parse(args); err != nil {
 		return &usageError{message: err.Error()}
 	}
@@ -229,17 +434,77 @@ func (a *App) handleAuthSet(args []string) error {
 		return err
 	}
 
-	cfg.APIKey = strings.TrimSpace(*apiKey)
+	name := a.activeProfile(cfg)
+	profile, err := cfg.Profile(name)
+	if err != nil && !errors.Is(err, config.ErrProfileNotFound) {
+		return &commandError{code: "CONFIG_ERROR", message: err.Error()}
+	}
+	if profile.APIKeyCommand != "" {
+		return &commandError{
+			code:    "CREDENTIAL_STORE_ERROR",
+			message: fmt.Sprintf("profile %s reads its API key from api_key_command; update the secret there", name),
+		}
+	}
+
+	if *encrypt {
+		cfg.CredentialStore = config.StoreEncrypted
+	}
+
+	store := config.StoreConfig
+	var migrated []string
+	if cfg.CredentialStore == config.StoreEncrypted {
+		store = config.StoreEncrypted
+		credentialsPath := config.CredentialsPath(path)
+		passphrase := a.passphrase
+		if _, err := os.Stat(credentialsPath); errors.Is(err, os.ErrNotExist) {
+			passphrase = a.newPassphrase
+		}
+
+		// Move plaintext keys left in other profiles into the store too, so enabling
+		// encryption leaves no API key in config.yaml.
+		keys := map[string]string{name: strings.TrimSpace(*apiKey)}
+		for _, other := range cfg.ProfileNames() {
+			otherProfile, err := cfg.Profile(other)
+			if other == name || err != nil || otherProfile.APIKey == "" || otherProfile.APIKeyCommand != "" {
+				continue
+			}
+			keys[other] = otherProfile.APIKey
+			migrated = append(migrated, other)
+		}
+
+		encrypted := config.NewEncryptedFileStore(credentialsPath, passphrase)
+		if err := encrypted.SetKeys(keys); err != nil {
+			return &commandError{code: "CREDENTIAL_STORE_ERROR", message: fmt.Sprintf("saving encrypted credentials: %v", err)}
+		}
+		// Drop any plaintext copy left from before encryption was enabled.
+		if profile.APIKey != "" {
+			cfg.SetAPIKey(name, "")
+		}
+		for _, other := range migrated {
+			cfg.SetAPIKey(other, "")
+		}
+	} else {
+		cfg.SetAPIKey(name, strings.TrimSpace(*apiKey))
+	}
+
 	if err := a.saveConfig(path, cfg); err != nil {
 		return &commandError{code: "CONFIG_ERROR", message: fmt.Sprintf("saving config: %v", err)}
 	}
 
-	return a.success("auth set", "API key saved", authSetData{ConfigPath: path})
+	message := fmt.Sprintf("API key saved for profile %s", name)
+	if len(migrated) > 0 {
+		message += fmt.Sprintf("; moved plaintext keys of %s to credentials.enc", strings.Join(migrated, ", "))
+	}
+	return a.success("auth set", message, authSetData{ConfigPath: path, Profile: name, Store: store, Migrated: migrated})
 }
 
 func (a *App) handleAuthStatus(args []string) error {
 	fs := flag.NewFlagSet("auth status", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
+
+	verify := fs.Bool("verify", false, "Check the API key against Holded and probe each API")
+	baseURL := fs.String("base-url", holded.DefaultBaseURL, "Holded API base URL used by --verify")
+	timeout := fs.Duration("timeout", a.timeout, "request timeout for --verify")
 	if err := fs.Parse(args); err != nil {
 		return &usageError{message: err.Error()}
 	}
@@ -247,29 +512,228 @@ func (a *App) handleAuthStatus(args []string) error {
 		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
 	}
 
-	_, cfg, err := a.readConfig()
+	cfgPath, cfg, err := a.readConfig()
+	if err != nil {
+		return err
+	}
+
+	credential, profile, err := a.resolveCredential("", cfgPath, cfg)
 	if err != nil {
 		return err
 	}
+	configured := credential.Key != ""
+
+	data := authStatusData{
+		Configured: configured,
+		Source:     string(credential.Source),
+		Profile:    credential.Profile,
+	}
+	if configured {
+		data.Fingerprint = holded.KeyFingerprint(credential.Key)
+		data.MaskedKey = holded.MaskKey(credential.Key)
+	}
+
+	if *verify {
+		if !configured {
+			return &commandError{
+				code:    "MISSING_API_KEY",
+				message: "missing Holded API key; use --api-key, HOLDED_API_KEY, or `holded auth set --api-key ...`",
+			}
+		}
+
+		data.Verification, err = a.verifyCredential(profileBaseURL(fs, *baseURL, profile), credential.Key, *timeout)
+		if err != nil {
+			return err
+		}
+	}
 
-	key, source := holded.ResolveAPIKey("", a.getenv("HOLDED_API_KEY"), cfg.APIKey)
-	configured := key != ""
+	if err := verificationError(data); err != nil {
+		return err
+	}
 
 	if a.jsonOutput {
-		return a.success("auth status", "authentication status loaded", authStatusData{
-			Configured: configured,
-			Source:     string(source),
-		})
+		return a.success("auth status", "authentication status loaded", data)
 	}
 
-	if configured {
-		fmt.Fprintf(a.out, "API key configured (source: %s)\n", source)
+	if configured && credential.Profile != "" {
+		fmt.Fprintf(a.out, "API key configured (source: %s, profile: %s)\n", credential.Source, credential.Profile)
+	} else if configured {
+		fmt.Fprintf(a.out, "API key configured (source: %s)\n", credential.Source)
 	} else {
 		fmt.Fprintln(a.out, "API key not configured")
+		return nil
+	}
+	fmt.Fprintf(a.out, "Key: %s (%s)\n", data.MaskedKey, data.Fingerprint)
+
+	if data.Verification != nil {
+		fmt.Fprintf(a.out, "Verification: %s (%s)\n", data.Verification.Status, data.Verification.BaseURL)
+		for _, access := range data.Verification.APIs {
+			state := "accessible"
+			if !access.Accessible {
+				state = fmt.Sprintf("not accessible (HTTP %d)", access.StatusCode)
+			}
+			fmt.Fprintf(a.out, "  %s\t%s\n", access.API, state)
+		}
+	}
+	return nil
+}
+
+// verifyCredential pings Holded with key and probes one read action per API.
+func (a *App) verifyCredential(baseURL, key string, timeout time.Duration) (*authVerificationData, error) {
+	client, err := a.newClient(baseURL, key, nil)
+	if err != nil {
+		return nil, &commandError{code: "INVALID_BASE_URL", message: err.Error()}
+	}
+	a.traceClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result := client.Verify(ctx, holded.DefaultPingPath, holded.DefaultAPIProbes)
+	verification := &authVerificationData{
+		Status:     result.Status,
+		BaseURL:    strings.TrimSpace(baseURL),
+		StatusCode: result.StatusCode,
+	}
+	if result.Err != nil {
+		verification.Error = result.Err.Error()
+	}
+	for _, access := range result.APIs {
+		verification.APIs = append(verification.APIs, apiAccessData{
+			API:        access.API,
+			Path:       access.Path,
+			Accessible: access.Accessible,
+			StatusCode: access.StatusCode,
+		})
+	}
+
+	return verification, nil
+}
+
+// verificationError turns a failed --verify into a command error carrying the status data.
+func verificationError(data authStatusData) error {
+	if data.Verification == nil {
+		return nil
+	}
+
+	switch data.Verification.Status {
+	case holded.KeyInvalid:
+		return &commandError{
+			code:    "INVALID_API_KEY",
+			message: fmt.Sprintf("API key %s was rejected by Holded (status %d)", data.MaskedKey, data.Verification.StatusCode),
+			details: data,
+		}
+	case holded.KeyUnreachable:
+		return &commandError{
+			code:    "NETWORK_ERROR",
+			message: fmt.Sprintf("could not verify API key: %s", data.Verification.Error),
+			details: data,
+		}
+	}
+	return nil
+}
+
+func (a *App) handleAuthList(args []string) error {
+	fs := flag.NewFlagSet("auth list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return &usageError{message: err.Error()}
+	}
+	if fs.NArg() > 0 {
+		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
+	}
+
+	cfgPath, cfg, err := a.readConfig()
+	if err != nil {
+		return err
+	}
+
+	// The encrypted store is not opened, so listing never asks for the passphrase: a
+	// profile counts as configured there as soon as credentials.enc exists.
+	_, statErr := os.Stat(config.CredentialsPath(cfgPath))
+	encrypted := cfg.CredentialStore == config.StoreEncrypted && statErr == nil
+
+	current := a.activeProfile(cfg)
+	data := authListData{Current: current, Profiles: make([]authProfileData, 0, len(cfg.Profiles)+1)}
+	for _, name := range cfg.ProfileNames() {
+		profile, _ := cfg.Profile(name)
+		var source holded.CredentialSource
+		switch {
+		case profile.APIKeyCommand != "":
+			source = holded.CredentialSourceCommand
+		case encrypted:
+			source = holded.CredentialSourceEncrypted
+		case profile.APIKey != "":
+			source = holded.CredentialSourceConfig
+		}
+		data.Profiles = append(data.Profiles, authProfileData{
+			Name:       name,
+			Configured: source != "",
+			Source:     string(source),
+			BaseURL:    profile.BaseURL,
+			Current:    name == current,
+		})
+	}
+
+	if a.jsonOutput {
+		return a.success("auth list", "profiles loaded", data)
+	}
+
+	for _, profile := range data.Profiles {
+		marker := " "
+		if profile.Current {
+			marker = "*"
+		}
+		status := "no API key"
+		if profile.Configured {
+			status = fmt.Sprintf("API key configured (%s)", profile.Source)
+		}
+		line := fmt.Sprintf("%s %s\t%s", marker, profile.Name, status)
+		if profile.BaseURL != "" {
+			line += "\t" + profile.BaseURL
+		}
+		fmt.Fprintln(a.out, line)
 	}
 	return nil
 }
 
+func (a *App) handleAuthUse(args []string) error {
+	if len(args) == 0 {
+		return &usageError{message: "auth use expects exactly one argument: <profile>"}
+	}
+	name := strings.TrimSpace(args[0])
+
+	fs := flag.NewFlagSet("auth use", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args[1:]); err != nil {
+		return &usageError{message: err.Error()}
+	}
+	if fs.NArg() > 0 {
+		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
+	}
+
+	path, cfg, err := a.readConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, err := cfg.Profile(name); err != nil {
+		return &commandError{code: "PROFILE_NOT_FOUND", message: err.Error()}
+	}
+
+	cfg.CurrentProfile = name
+	if name == config.DefaultProfile {
+		if _, ok := cfg.Profiles[name]; !ok {
+			cfg.CurrentProfile = ""
+		}
+	}
+	if err := a.saveConfig(path, cfg); err != nil {
+		return &commandError{code: "CONFIG_ERROR", message: fmt.Sprintf("saving config: %v", err)}
+	}
+
+	return a.success("auth use", fmt.Sprintf("active profile set to %s", name), authUseData{ConfigPath: path, Profile: name})
+}
+
 func (a *App) handlePing(args []string) error {
 	fs := flag.NewFlagSet("ping", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
@@ -278,6 +742,8 @@ func (a *App) handlePing(args []string) error {
 	baseURL := fs.String("base-url", holded.DefaultBaseURL, "Holded API base URL")
 	path := fs.String("path", holded.DefaultPingPath, "Holded API ping path")
 	timeout := fs.Duration("timeout", a.timeout, "request timeout")
+	retry := addRetryFlags(fs, false)
+	rateLimit := addRateLimitFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
 		return &usageError{message: err.Error()}
@@ -286,45 +752,53 @@ func (a *App) handlePing(args []string) error {
 		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
 	}
 
-	_, cfg, err := a.readConfig()
+	cfgPath, cfg, err := a.readConfig()
 	if err != nil {
 		return err
 	}
 
-	key, source := holded.ResolveAPIKey(*apiKey, a.getenv("HOLDED_API_KEY"), cfg.APIKey)
-	if key == "" {
+	credential, profile, err := a.resolveCredential(*apiKey, cfgPath, cfg)
+	if err != nil {
+		return err
+	}
+	if credential.Key == "" {
 		return &commandError{
 			code:    "MISSING_API_KEY",
 			message: "missing Holded API key; use --api-key, HOLDED_API_KEY, or `holded auth set --api-key ...`",
 		}
 	}
 
-	client, err := a.newClient(*baseURL, key, nil)
+	*baseURL = profileBaseURL(fs, *baseURL, profile)
+	client, err := a.newClient(*baseURL, credential.Key, nil)
 	if err != nil {
 		return &commandError{code: "INVALID_BASE_URL", message: err.Error()}
 	}
+	a.traceClient(client)
+	client.SetRetryPolicy(retry.policy())
+	if limiter := rateLimit.limiter(fs, profile.RateLimit, cfgPath); limiter != nil {
+		client.SetRateLimiter(limiter)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
-	statusCode, err := client.Ping(ctx, *path)
+	response, err := client.Do(ctx, holded.Request{Method: http.MethodGet, Path: *path})
 	if err != nil {
 		var apiErr *holded.APIError
 		if errors.As(err, &apiErr) {
-			message := fmt.Sprintf("ping failed with status %d", apiErr.StatusCode)
-			if apiErr.BodySnippet != "" {
-				message = fmt.Sprintf("%s: %s", message, apiErr.BodySnippet)
-			}
-			return &commandError{code: "API_ERROR", message: message}
+			message := fmt.Sprintf("ping failed with status %d%s", apiErr.StatusCode, attemptsSuffix(response.Attempts))
+			return newAPICommandError(message, apiErr, response)
 		}
-		return &commandError{code: "NETWORK_ERROR", message: fmt.Sprintf("ping failed: %v", err)}
+		return &commandError{code: "NETWORK_ERROR", message: fmt.Sprintf("ping failed%s: %v", attemptsSuffix(response.Attempts), err)}
 	}
 
 	return a.success("ping", "Holded API reachable", pingData{
 		BaseURL:          strings.TrimSpace(*baseURL),
 		Path:             strings.TrimSpace(*path),
-		StatusCode:       statusCode,
-		CredentialSource: string(source),
+		StatusCode:       response.StatusCode,
+		Attempts:         response.Attempts,
+		CredentialSource: string(credential.Source),
+		Profile:          credential.Profile,
 	})
 }
 
@@ -340,6 +814,12 @@ func (a *App) handleActions(args []string) error {
 		return a.handleActionsDescribe(args[1:])
 	case "run":
 		return a.handleActionsRun(args[1:])
+	case "refresh":
+		return a.handleActionsRefresh(args[1:])
+	case "import":
+		return a.handleActionsImport(args[1:])
+	case "diff":
+		return a.handleActionsDiff(args[1:])
 	default:
 		return &usageError{message: fmt.Sprintf("unknown actions subcommand: %s", args[0])}
 	}
@@ -351,6 +831,8 @@ func (a *App) handleActionsList(args []string) error {
 
 	filter := fs.String("filter", "", "Filter by id, operation, method or path")
 	timeout := fs.Duration("timeout", a.catalogTimeout, "catalog loading timeout")
+	templateText := fs.String("template", "", "Go text/template used to render the actions list")
+	templateFile := fs.String("template-file", "", "File with a Go text/template used to render the actions list")
 
 	if err := fs.Parse(args); err != nil {
 		return &usageError{message: err.Error()}
@@ -358,6 +840,10 @@ func (a *App) handleActionsList(args []string) error {
 	if fs.NArg() > 0 {
 		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
 	}
+	tmpl, err := a.outputTemplate(*templateText, *templateFile)
+	if err != nil {
+		return err
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
@@ -413,6 +899,20 @@ func (a *App) handleActionsList(args []string) error {
 	if a.jsonOutput {
 		return a.success("actions list", "actions catalog loaded", data)
 	}
+	if tmpl != nil {
+		templateData, err := toTemplateData(data.Actions)
+		if err != nil {
+			return err
+		}
+		return executeTemplate(a.out, tmpl, templateData)
+	}
+	if a.output != "" {
+		encoded, err := json.Marshal(data.Actions)
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.output, encoded, a.columns)
+	}
 
 	for _, action := range actionsList {
 		label := action.ID
@@ -496,6 +996,10 @@ func (a *App) handleActionsDescribe(args []string) error {
 		}
 	}
 
+	if action.NoMetadata {
+		fmt.Fprintln(a.out, "\nParameters and request body are not recorded in this catalog; run `holded actions refresh` to fetch them.")
+	}
+
 	if action.RequestBody != nil {
 		fmt.Fprintln(a.out, "\nRequest body:")
 		required := "optional"
@@ -517,160 +1021,1135 @@ func (a *App) handleActionsDescribe(args []string) error {
 	return nil
 }
 
-func (a *App) handleActionsRun(args []string) error {
-	if len(args) == 0 {
-		return &usageError{message: "actions run expects exactly one argument: <action-id|operation-id>"}
-	}
-	actionRef := args[0]
-
-	fs := flag.NewFlagSet("actions run", flag.ContinueOnError)
+func (a *App) handleActionsRefresh(args []string) error {
+	fs := flag.NewFlagSet("actions refresh", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 
-	apiKey := fs.String("api-key", "", "Holded API key")
-	baseURL := fs.String("base-url", holded.DefaultBaseURL, "Holded API base URL")
-	body := fs.String("body", "", "JSON request body")
-	bodyFile := fs.String("body-file", "", "Path to a JSON request body file")
-	filePath := fs.String("file", "", "Path to upload as multipart/form-data field 'file'")
-	skipValidation := fs.Bool("skip-validation", false, "Skip request body validation against action metadata")
-	timeout := fs.Duration("timeout", a.requestTimeout, "request timeout")
-	catalogTimeout := fs.Duration("catalog-timeout", a.catalogTimeout, "catalog loading timeout")
-
-	var pathPairs kvValues
-	var queryPairs kvValues
-	var headerPairs kvValues
-	fs.Var(&pathPairs, "path", "Path parameter key=value (repeatable)")
-	fs.Var(&queryPairs, "query", "Query parameter key=value (repeatable)")
-	fs.Var(&headerPairs, "header", "Additional request header key=value (repeatable)")
-
-	if err := fs.Parse(args[1:]); err != nil {
+	timeout := fs.Duration("timeout", a.refreshTimeout, "docs fetching timeout")
+	if err := fs.Parse(args); err != nil {
 		return &usageError{message: err.Error()}
 	}
 	if fs.NArg() > 0 {
 		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
 	}
 
-	if strings.TrimSpace(*body) != "" && strings.TrimSpace(*bodyFile) != "" {
-		return &usageError{message: "use either --body or --body-file, not both"}
-	}
-	if strings.TrimSpace(*filePath) != "" && (strings.TrimSpace(*body) != "" || strings.TrimSpace(*bodyFile) != "") {
-		return &usageError{message: "use either --file or --body/--body-file, not both"}
+	path, err := a.configPath()
+	if err != nil {
+		return &commandError{code: "CONFIG_ERROR", message: fmt.Sprintf("resolving config path: %v", err)}
 	}
+	cachePath := config.CatalogCachePath(path)
 
-	requestBody, err := readBodyInput(*body, *bodyFile)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	catalog, err := a.fetchCatalog(ctx, a.catalogHTTP)
 	if err != nil {
-		return &commandError{code: "INVALID_BODY", message: err.Error()}
+		return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("fetching actions catalog: %v", err)}
 	}
 
-	pathParams, err := pathPairs.Map()
-	if err != nil {
+	if err := a.saveCatalog(cachePath, catalog); err != nil {
+		return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("saving actions catalog: %v", err)}
+	}
+
+	data := actionsRefreshData{
+		GeneratedAt: catalog.GeneratedAt.Format(time.RFC3339),
+		Source:      catalog.Source,
+		Count:       len(catalog.Actions),
+		CachePath:   cachePath,
+	}
+
+	if a.jsonOutput {
+		return a.success("actions refresh", "actions catalog refreshed", data)
+	}
+
+	fmt.Fprintf(a.out, "Saved %d actions from %s to %s\n", data.Count, data.Source, data.CachePath)
+	return nil
+}
+
+func (a *App) handleActionsImport(args []string) error {
+	fs := flag.NewFlagSet("actions import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	specPath := fs.String("openapi", "", "Path to an OpenAPI 3 document (JSON or YAML)")
+	replace := fs.Bool("replace", false, "Replace the current catalog instead of merging into it")
+	timeout := fs.Duration("timeout", a.catalogTimeout, "catalog loading timeout")
+	if err := fs.Parse(args); err != nil {
 		return &usageError{message: err.Error()}
 	}
-	query, err := queryPairs.Values()
+	if fs.NArg() > 0 {
+		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
+	}
+	if strings.TrimSpace(*specPath) == "" {
+		return &usageError{message: "missing required flag: --openapi"}
+	}
+
+	path, err := a.configPath()
 	if err != nil {
-		return &usageError{message: err.Error()}
+		return &commandError{code: "CONFIG_ERROR", message: fmt.Sprintf("resolving config path: %v", err)}
 	}
-	headers, err := headerPairs.Map()
+	cachePath := config.CatalogCachePath(path)
+
+	imported, err := actions.LoadCatalogFromOpenAPI(strings.TrimSpace(*specPath))
 	if err != nil {
-		return &usageError{message: err.Error()}
+		return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("importing OpenAPI document: %v", err)}
 	}
 
-	if strings.TrimSpace(*filePath) != "" {
-		requestBody, headers, err = readMultipartFileInput(*filePath, headers)
+	catalog := imported
+	if !*replace {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		base, err := a.loadCatalog(ctx, a.catalogHTTP)
 		if err != nil {
-			return &commandError{code: "INVALID_BODY", message: err.Error()}
+			return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("loading actions catalog: %v", err)}
 		}
+		catalog = actions.MergeCatalogs(base, imported)
 	}
 
-	_, cfg, err := a.readConfig()
-	if err != nil {
+	if err := a.saveCatalog(cachePath, catalog); err != nil {
+		return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("saving actions catalog: %v", err)}
+	}
+
+	data := actionsImportData{
+		Source:    imported.Source,
+		Imported:  len(imported.Actions),
+		Count:     len(catalog.Actions),
+		Replaced:  *replace,
+		CachePath: cachePath,
+	}
+
+	if a.jsonOutput {
+		return a.success("actions import", "OpenAPI document imported", data)
+	}
+
+	fmt.Fprintf(a.out, "Imported %d actions from %s (%d total) to %s\n", data.Imported, strings.TrimSpace(*specPath), data.Count, data.CachePath)
+	return nil
+}
+
+func (a *App) handleActionsDiff(args []string) error {
+	if len(args) < 2 {
+		return &usageError{message: "actions diff expects exactly two arguments: <old.json> <new.json>"}
+	}
+	oldPath, newPath := args[0], args[1]
+
+	fs := flag.NewFlagSet("actions diff", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	failOnBreaking := fs.Bool("fail-on-breaking", false, "Exit with BREAKING_CHANGES when breaking changes are found")
+	if err := fs.Parse(args[2:]); err != nil {
+		return &usageError{message: err.Error()}
+	}
+	if fs.NArg() > 0 {
+		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
+	}
+
+	oldCatalog, err := actions.LoadCatalogFile(oldPath)
+	if err != nil {
+		return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("loading old catalog: %v", err)}
+	}
+	newCatalog, err := actions.LoadCatalogFile(newPath)
+	if err != nil {
+		return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("loading new catalog: %v", err)}
+	}
+
+	diff := actions.DiffCatalogs(oldCatalog, newCatalog)
+	data := actionsDiffData{Old: oldPath, New: newPath, CatalogDiff: diff}
+
+	if *failOnBreaking && diff.Breaking > 0 {
+		if !a.jsonOutput {
+			printCatalogDiff(a.out, diff)
+		}
+		return &commandError{
+			code:    "BREAKING_CHANGES",
+			message: fmt.Sprintf("found %d breaking changes", diff.Breaking),
+			details: data,
+		}
+	}
+
+	if a.jsonOutput {
+		return a.success("actions diff", "catalogs compared", data)
+	}
+
+	printCatalogDiff(a.out, diff)
+	return nil
+}
+
+func (a *App) handleActionsRun(args []string) error {
+	if len(args) == 0 {
+		return &usageError{message: "actions run expects exactly one argument: <action-id|operation-id>"}
+	}
+	actionRef := args[0]
+
+	fs := flag.NewFlagSet("actions run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	apiKey := fs.String("api-key", "", "Holded API key")
+	baseURL := fs.String("base-url", holded.DefaultBaseURL, "Holded API base URL")
+	body := fs.String("body", "", "JSON request body")
+	bodyFile := fs.String("body-file", "", "Path to a JSON request body file")
+	filePath := fs.String("file", "", "Path to upload as multipart/form-data field 'file'")
+	skipValidation := fs.Bool("skip-validation", false, "Skip path, query and body validation against action metadata")
+	timeout := fs.Duration("timeout", a.requestTimeout, "request timeout")
+	catalogTimeout := fs.Duration("catalog-timeout", a.catalogTimeout, "catalog loading timeout")
+	retry := addRetryFlags(fs, true)
+	rateLimit := addRateLimitFlags(fs)
+	all := fs.Bool("all", false, "Follow pages of a list action and concatenate the results")
+	maxPages := fs.Int("max-pages", 0, "Maximum pages to fetch with --all (0 means no limit)")
+	pageSize := fs.Int("page-size", 0, "Items per page requested with --all")
+	outputFile := fs.String("output-file", "", "Write the response payload (raw or base64-decoded) to this file")
+	queryOutput := fs.String("query-output", "", "jq-style expression applied to the decoded response before printing")
+	templateText := fs.String("template", "", "Go text/template used to render the decoded response")
+	templateFile := fs.String("template-file", "", "File with a Go text/template used to render the decoded response")
+	humanizeOutput := fs.Bool("humanize", false, "Show Holded timestamps as ISO dates and amounts as money; accept ISO dates in --body")
+	timezone := fs.String("timezone", "", "IANA timezone used by --humanize and template dates (default: timezone from config.yaml or local)")
+	locale := fs.String("locale", "", "Number format used by --humanize, such as en or es (default: locale from config.yaml or en)")
+	dryRun := fs.Bool("dry-run", false, "Print the HTTP request instead of sending it")
+	emit := fs.String("emit", "", "Render the request as curl, httpie or http-file instead of sending it")
+	yes := fs.Bool("yes", false, "Run destructive actions without asking for confirmation")
+
+	var pathPairs kvValues
+	var queryPairs kvValues
+	var headerPairs kvValues
+	fs.Var(&pathPairs, "path", "Path parameter key=value (repeatable)")
+	fs.Var(&queryPairs, "query", "Query parameter key=value (repeatable)")
+	fs.Var(&headerPairs, "header", "Additional request header key=value (repeatable)")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return &usageError{message: err.Error()}
+	}
+	if fs.NArg() > 0 {
+		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
+	}
+
+	if strings.TrimSpace(*body) != "" && strings.TrimSpace(*bodyFile) != "" {
+		return &usageError{message: "use either --body or --body-file, not both"}
+	}
+	if strings.TrimSpace(*filePath) != "" && (strings.TrimSpace(*body) != "" || strings.TrimSpace(*bodyFile) != "") {
+		return &usageError{message: "use either --file or --body/--body-file, not both"}
+	}
+	if !*all && (*maxPages != 0 || *pageSize != 0) {
+		return &usageError{message: "--max-pages and --page-size require --all"}
+	}
+	if *maxPages < 0 || *pageSize < 0 {
+		return &usageError{message: "--max-pages and --page-size must not be negative"}
+	}
+	if strings.TrimSpace(*outputFile) != "" && (*all || a.output != "") {
+		return &usageError{message: "--output-file cannot be combined with --all or --output"}
+	}
+	var outputQuery *jq.Query
+	if expr := strings.TrimSpace(*queryOutput); expr != "" {
+		if a.output == outputNDJSON || strings.TrimSpace(*outputFile) != "" {
+			return &usageError{message: "--query-output cannot be combined with --output ndjson or --output-file"}
+		}
+		parsed, err := jq.Parse(expr)
+		if err != nil {
+			return &usageError{message: fmt.Sprintf("invalid --query-output: %v", err)}
+		}
+		outputQuery = parsed
+	}
+	tmpl, err := a.outputTemplate(*templateText, *templateFile)
+	if err != nil {
+		return err
+	}
+	if tmpl != nil && strings.TrimSpace(*outputFile) != "" {
+		return &usageError{message: "--template cannot be combined with --output-file"}
+	}
+	if !*humanizeOutput && strings.TrimSpace(*locale) != "" {
+		return &usageError{message: "--locale requires --humanize"}
+	}
+	if !*humanizeOutput && tmpl == nil && strings.TrimSpace(*timezone) != "" {
+		return &usageError{message: "--timezone requires --humanize or --template"}
+	}
+	if *humanizeOutput && (a.output == outputNDJSON || strings.TrimSpace(*outputFile) != "") {
+		return &usageError{message: "--humanize cannot be combined with --output ndjson or --output-file"}
+	}
+	if *dryRun && *all {
+		return &usageError{message: "--dry-run cannot be combined with --all"}
+	}
+	switch *emit {
+	case "", emitCurl, emitHTTPie, emitHTTPFile:
+	default:
+		return &usageError{message: fmt.Sprintf("unsupported --emit %q; supported: %s, %s, %s", *emit, emitCurl, emitHTTPie, emitHTTPFile)}
+	}
+	if *emit != "" && (*dryRun || *all) {
+		return &usageError{message: "--emit cannot be combined with --dry-run or --all"}
+	}
+
+	requestBody, err := readBodyInput(*body, *bodyFile)
+	if err != nil {
+		return &commandError{code: "INVALID_BODY", message: err.Error()}
+	}
+
+	pathParams, err := pathPairs.Map()
+	if err != nil {
+		return &usageError{message: err.Error()}
+	}
+	query, err := queryPairs.Values()
+	if err != nil {
+		return &usageError{message: err.Error()}
+	}
+	headers, err := headerPairs.Map()
+	if err != nil {
+		return &usageError{message: err.Error()}
+	}
+
+	if strings.TrimSpace(*filePath) != "" {
+		requestBody, headers, err = readMultipartFileInput(*filePath, headers)
+		if err != nil {
+			return &commandError{code: "INVALID_BODY", message: err.Error()}
+		}
+	}
+
+	cfgPath, cfg, err := a.readConfig()
+	if err != nil {
+		return err
+	}
+
+	resolve := a.resolveCredential
+	if *dryRun || *emit != "" {
+		// The key is redacted from printed requests, so the credential stores are not needed.
+		resolve = a.resolveLocalCredential
+	}
+	credential, profile, err := resolve(*apiKey, cfgPath, cfg)
+	if err != nil {
+		return err
+	}
+	if credential.Key == "" && !*dryRun && *emit == "" {
+		return &commandError{
+			code:    "MISSING_API_KEY",
+			message: "missing Holded API key; use --api-key, HOLDED_API_KEY, or `holded auth set --api-key ...`",
+		}
+	}
+
+	catalogCtx, cancelCatalog := context.WithTimeout(context.Background(), *catalogTimeout)
+	defer cancelCatalog()
+
+	catalog, err := a.loadCatalog(catalogCtx, a.catalogHTTP)
+	if err != nil {
+		return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("loading actions catalog: %v", err)}
+	}
+
+	action, err := catalog.Find(actionRef)
+	if err != nil {
+		return &commandError{code: "ACTION_NOT_FOUND", message: err.Error()}
+	}
+	if *all && !action.Paginated() {
+		return &usageError{message: fmt.Sprintf("--all requires a paginated list action; %s is not one", action.ID)}
+	}
+	if *pageSize > 0 && !action.AcceptsQueryParameter(holded.PageSizeParam) {
+		return &usageError{message: fmt.Sprintf("--page-size sets the %q query parameter, which %s does not declare", holded.PageSizeParam, action.ID)}
+	}
+
+	if err := a.checkPolicy(profile, action); err != nil {
+		return err
+	}
+
+	var humanizeOpts *humanize.Options
+	if *humanizeOutput {
+		opts, err := humanizeOptions(*timezone, *locale, cfg)
+		if err != nil {
+			return err
+		}
+		humanizeOpts = &opts
+
+		if strings.TrimSpace(*filePath) == "" {
+			if requestBody, err = humanize.Body(requestBody, opts.Location); err != nil {
+				return &commandError{code: "INVALID_BODY", message: err.Error()}
+			}
+		}
+	}
+
+	if tmpl != nil {
+		loc, err := outputLocation(*timezone, cfg)
+		if err != nil {
+			return err
+		}
+		setTemplateLocation(tmpl, loc)
+	}
+
+	if !*skipValidation && action.NoMetadata && (len(query) > 0 || len(bytes.TrimSpace(requestBody)) > 0) {
+		fmt.Fprintf(a.errOut, "warning: %s has no parameter or body metadata in %s; --query and --body are not validated (run `holded actions refresh`)\n", action.ID, catalog.Source)
+	}
+
+	if !*skipValidation {
+		if issues := actions.ValidateRequestParameters(action, pathParams, query); len(issues) > 0 {
+			return &commandError{
+				code:    "INVALID_PARAMS",
+				message: formatValidationIssues("--path/--query", issues),
+				details: issues,
+			}
+		}
+	}
+
+	if !*skipValidation && strings.TrimSpace(*filePath) == "" {
+		if issues := actions.ValidateBodyParameters(action, requestBody); len(issues) > 0 {
+			return &commandError{
+				code:    "INVALID_BODY_PARAMS",
+				message: formatValidationIssues("--body", issues),
+				details: issues,
+			}
+		}
+	}
+
+	resolvedPath, err := actions.ResolvePathTemplate(action.Path, pathParams)
+	if err != nil {
+		return &usageError{message: err.Error()}
+	}
+
+	client, err := a.newClient(profileBaseURL(fs, *baseURL, profile), credential.Key, nil)
+	if err != nil {
+		return &commandError{code: "INVALID_BASE_URL", message: err.Error()}
+	}
+	a.traceClient(client)
+	client.SetRetryPolicy(retry.policy())
+	if limiter := rateLimit.limiter(fs, profile.RateLimit, cfgPath); limiter != nil {
+		client.SetRateLimiter(limiter)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	request := holded.Request{
+		Method:  action.Method,
+		Path:    resolvedPath,
+		Query:   query,
+		Body:    requestBody,
+		Headers: headers,
+	}
+
+	if *dryRun {
+		req, err := client.BuildRequest(ctx, request)
+		if err != nil {
+			return &commandError{code: "INVALID_REQUEST", message: err.Error()}
+		}
+		return a.printDryRun(action, req, requestBody)
+	}
+
+	if *emit != "" {
+		req, err := client.BuildRequest(ctx, request)
+		if err != nil {
+			return &commandError{code: "INVALID_REQUEST", message: err.Error()}
+		}
+		content, err := renderRequest(*emit, req, requestBody, strings.TrimSpace(*filePath))
+		if err != nil {
+			return &commandError{code: "INVALID_REQUEST", message: err.Error()}
+		}
+		if a.jsonOutput {
+			return a.success("actions run", "request rendered, not sent", emitData{ActionID: action.ID, Format: *emit, Content: content})
+		}
+		fmt.Fprintln(a.out, content)
+		return nil
+	}
+
+	if action.IsDestructive() && !*yes {
+		req, err := client.BuildRequest(ctx, request)
+		if err != nil {
+			return &commandError{code: "INVALID_REQUEST", message: err.Error()}
+		}
+		if err := a.confirmDestructive(action, req); err != nil {
+			return err
+		}
+	}
+
+	pageOpts := holded.PageOptions{MaxPages: *maxPages, PageSize: *pageSize}
+	writeItem := func(item json.RawMessage) error { return writeNDJSONLine(a.out, item) }
+
+	var response holded.Response
+	var pagination *paginationData
+	started := time.Now()
+	switch {
+	case a.output == outputNDJSON && *all:
+		var stats holded.PageStats
+		stats, err = client.ForEachPage(ctx, request, pageOpts, writeItem)
+		response = holded.Response{StatusCode: stats.StatusCode, Attempts: stats.Attempts}
+	case a.output == outputNDJSON:
+		response, err = client.StreamItems(ctx, request, writeItem)
+	case *all:
+		var stats holded.PageStats
+		response, stats, err = fetchAllPages(ctx, client, request, pageOpts)
+		pagination = &paginationData{Pages: stats.Pages, Items: stats.Items}
+	default:
+		response, err = client.Do(ctx, request)
+	}
+
+	runErr := actionRunError(action.ID, response, err)
+	a.recordAudit(cfgPath, cfg, audit.Entry{
+		Time:             started.UTC(),
+		Profile:          credential.Profile,
+		CredentialSource: string(credential.Source),
+		ActionID:         action.ID,
+		Method:           action.Method,
+		Path:             resolvedPath,
+		Query:            query,
+		BodySHA256:       audit.HashBody(requestBody),
+		BodySize:         len(requestBody),
+		StatusCode:       response.StatusCode,
+		DurationMS:       time.Since(started).Milliseconds(),
+	}, runErr)
+	if runErr != nil {
+		return runErr
+	}
+
+	if a.output == outputNDJSON {
+		return nil
+	}
+
+	var written *outputFileData
+	var decoded any
+	if strings.TrimSpace(*outputFile) != "" {
+		written, err = writeOutputFile(*outputFile, response)
+		if err != nil {
+			return err
+		}
+	} else {
+		decoded = decodeResponseBody(response.Body)
+	}
+
+	rendered := response.Body
+	if outputQuery != nil {
+		results, err := outputQuery.Run(decoded)
+		if err != nil {
+			return &commandError{code: "QUERY_ERROR", message: fmt.Sprintf("evaluating --query-output: %v", err)}
+		}
+		if humanizeOpts != nil {
+			for i, result := range results {
+				results[i] = humanize.Response(result, *humanizeOpts)
+			}
+		}
+		if a.output == "" && !a.jsonOutput && tmpl == nil {
+			return writeQueryResults(a.out, results)
+		}
+
+		// Results are always an array, however many the expression yields, so the
+		// shape of --json, --output and --template input does not depend on the data.
+		if results == nil {
+			results = []any{}
+		}
+		decoded = results
+		if rendered, err = json.Marshal(decoded); err != nil {
+			return &commandError{code: "QUERY_ERROR", message: fmt.Sprintf("encoding --query-output result: %v", err)}
+		}
+	} else if humanizeOpts != nil && len(response.Body) > 0 {
+		decoded = humanize.Response(decoded, *humanizeOpts)
+		if rendered, err = json.Marshal(decoded); err != nil {
+			return &commandError{code: "INVALID_RESPONSE", message: fmt.Sprintf("encoding humanized response: %v", err)}
+		}
+	}
+
+	if tmpl != nil {
+		return executeTemplate(a.out, tmpl, decoded)
+	}
+
+	if a.output != "" {
+		if err := render(a.out, a.output, rendered, a.columns); err != nil {
+			return &commandError{code: "INVALID_RESPONSE", message: fmt.Sprintf("rendering %s output: %v", a.output, err)}
+		}
+		return nil
+	}
+
+	if a.jsonOutput {
+		return a.success("actions run", "action executed", actionRunData{
+			ActionID:         action.ID,
+			API:              action.API,
+			OperationID:      action.OperationID,
+			Method:           action.Method,
+			Path:             resolvedPath,
+			StatusCode:       response.StatusCode,
+			Attempts:         response.Attempts,
+			CredentialSource: string(credential.Source),
+			Profile:          credential.Profile,
+			Pagination:       pagination,
+			OutputFile:       written,
+			Response:         decoded,
+		})
+	}
+
+	if pagination != nil {
+		fmt.Fprintf(a.out, "%s %s -> HTTP %d (%d pages, %d items)\n", action.Method, resolvedPath, response.StatusCode, pagination.Pages, pagination.Items)
+	} else {
+		fmt.Fprintf(a.out, "%s %s -> HTTP %d\n", action.Method, resolvedPath, response.StatusCode)
+	}
+	if written != nil {
+		fmt.Fprintf(a.out, "Wrote %d bytes (%s) to %s\n", written.Size, written.ContentType, written.Path)
+		return nil
+	}
+	if len(response.Body) > 0 {
+		fmt.Fprintln(a.out)
+		fmt.Fprintln(a.out, prettyBody(rendered))
+	}
+
+	return nil
+}
+
+func (a *App) loadLocalCatalog(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+	path, err := a.configPath()
+	if err != nil {
+		return actions.Catalog{}, fmt.Errorf("resolving config path: %w", err)
+	}
+
+	return actions.LoadLocalCatalog(config.CatalogCachePath(path))
+}
+
+func (a *App) handleAudit(args []string) error {
+	if len(args) == 0 {
+		return &usageError{message: "missing audit subcommand"}
+	}
+
+	switch args[0] {
+	case "list":
+		return a.handleAuditList(args[1:])
+	default:
+		return &usageError{message: fmt.Sprintf("unknown audit subcommand: %s", args[0])}
+	}
+}
+
+func (a *App) handleAuditList(args []string) error {
+	fs := flag.NewFlagSet("audit list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	since := fs.String("since", "", "Only entries newer than a duration (24h), date (2006-01-02) or RFC3339 time")
+	actionPattern := fs.String("action", "", "Only entries whose action ID matches this glob")
+	if err := fs.Parse(args); err != nil {
+		return &usageError{message: err.Error()}
+	}
+	if fs.NArg() > 0 {
+		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
+	}
+
+	filter := audit.Filter{Action: strings.TrimSpace(*actionPattern)}
+	if value := strings.TrimSpace(*since); value != "" {
+		t, err := parseSince(value, time.Now())
+		if err != nil {
+			return &usageError{message: err.Error()}
+		}
+		filter.Since = t
+	}
+
+	path, cfg, err := a.readConfig()
+	if err != nil {
 		return err
 	}
+	logPath := cfg.AuditLogPath(path)
+
+	entries, err := audit.Read(logPath, filter)
+	if err != nil {
+		return &commandError{code: "AUDIT_LOG_ERROR", message: fmt.Sprintf("reading audit log: %v", err)}
+	}
+
+	data := auditListData{LogPath: logPath, Count: len(entries), Entries: entries}
+	if a.jsonOutput {
+		return a.success("audit list", "audit log loaded", data)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(a.out, "no audit entries")
+		return nil
+	}
+	for _, entry := range entries {
+		result := strconv.Itoa(entry.StatusCode)
+		if entry.ErrorCode != "" {
+			result += " " + entry.ErrorCode
+		}
+		fmt.Fprintf(a.out, "%s\t%s\t%s %s\t%s\t%dms\t%s\n",
+			entry.Time.Local().Format(time.RFC3339), entry.ActionID, entry.Method, entry.Path, result, entry.DurationMS, entry.Profile)
+	}
+	return nil
+}
+
+func (a *App) handleMock(args []string) error {
+	if len(args) == 0 {
+		return &usageError{message: "missing mock subcommand"}
+	}
+
+	switch args[0] {
+	case "serve":
+		return a.handleMockServe(args[1:])
+	default:
+		return &usageError{message: fmt.Sprintf("unknown mock subcommand: %s", args[0])}
+	}
+}
+
+// handleMockServe runs an in-memory Holded API for every catalog action until interrupted.
+// Requests must carry the resolved API key; without one any non-empty key is accepted.
+func (a *App) handleMockServe(args []string) error {
+	fs := flag.NewFlagSet("mock serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	port := fs.Int("port", 8080, "Port to listen on (0 picks a free port)")
+	host := fs.String("host", "127.0.0.1", "Interface to listen on")
+	apiKey := fs.String("api-key", "", "API key clients must send in the key header (or HOLDED_MOCK_API_KEY)")
+	timeout := fs.Duration("timeout", a.catalogTimeout, "catalog loading timeout")
+	if err := fs.Parse(args); err != nil {
+		return &usageError{message: err.Error()}
+	}
+	if fs.NArg() > 0 {
+		return &usageError{message: fmt.Sprintf("unexpected argument: %s", fs.Arg(0))}
+	}
+	if *port < 0 || *port > 65535 {
+		return &usageError{message: fmt.Sprintf("invalid --port %d: must be between 0 and 65535", *port)}
+	}
+
+	// The mock never uses the configured credentials: a real key would end up in
+	// every script pointed at it, and resolving it may prompt for a passphrase.
+	key := strings.TrimSpace(*apiKey)
+	if key == "" {
+		key = strings.TrimSpace(a.getenv("HOLDED_MOCK_API_KEY"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	catalog, err := a.loadCatalog(ctx, a.catalogHTTP)
+	if err != nil {
+		return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("loading actions catalog: %v", err)}
+	}
+
+	listener, err := a.listen("tcp", net.JoinHostPort(strings.TrimSpace(*host), strconv.Itoa(*port)))
+	if err != nil {
+		return &commandError{code: "LISTEN_ERROR", message: fmt.Sprintf("starting mock server: %v", err)}
+	}
+
+	handler := mock.NewServer(catalog, key)
+	handler.SetLogger(a.errOut)
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	serveCtx, stop := a.notifyContext()
+	defer stop()
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	baseURL := "http://" + listener.Addr().String()
+	data := mockServeData{URL: baseURL, Actions: handler.Routes(), AnyKey: key == ""}
+	if a.jsonOutput {
+		if err := a.success("mock serve", "mock Holded API listening", data); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(a.out, "Mock Holded API listening on %s (%d actions)\n", baseURL, data.Actions)
+		if data.AnyKey {
+			fmt.Fprintln(a.out, "Any non-empty key header is accepted")
+		}
+		fmt.Fprintf(a.out, "Use --base-url %s with ping and actions run; press Ctrl+C to stop\n", baseURL)
+	}
+
+	select {
+	case err := <-served:
+		return &commandError{code: "LISTEN_ERROR", message: fmt.Sprintf("mock server stopped: %v", err)}
+	case <-serveCtx.Done():
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return &commandError{code: "LISTEN_ERROR", message: fmt.Sprintf("stopping mock server: %v", err)}
+	}
+	return nil
+}
+
+func notifyInterrupt() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// parseSince accepts a duration before now (24h, 90m), a date (2006-01-02, local time) or an RFC3339 time.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("--since duration must be positive: %s", value)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q: expected a duration (24h), a date (2006-01-02) or an RFC3339 time", value)
+}
+
+// actionRunError maps a failed actions run request to its command error; it returns nil for a nil err.
+func actionRunError(actionID string, response holded.Response, err error) error {
+	if err == nil {
+		return nil
+	}
 
-	key, source := holded.ResolveAPIKey(*apiKey, a.getenv("HOLDED_API_KEY"), cfg.APIKey)
-	if key == "" {
-		return &commandError{
-			code:    "MISSING_API_KEY",
-			message: "missing Holded API key; use --api-key, HOLDED_API_KEY, or `holded auth set --api-key ...`",
+	if errors.Is(err, holded.ErrNotArray) {
+		return &commandError{code: "INVALID_RESPONSE", message: fmt.Sprintf("paginating %s: %v", actionID, err)}
+	}
+
+	var apiErr *holded.APIError
+	if errors.As(err, &apiErr) {
+		message := fmt.Sprintf("action failed with status %d%s", apiErr.StatusCode, attemptsSuffix(response.Attempts))
+		return newAPICommandError(message, apiErr, response)
+	}
+
+	return &commandError{code: "NETWORK_ERROR", message: fmt.Sprintf("action request failed%s: %v", attemptsSuffix(response.Attempts), err)}
+}
+
+// newAPICommandError maps apiErr to an error code by status and carries the parsed
+// Holded error as details. message is suffixed with the body snippet.
+func newAPICommandError(message string, apiErr *holded.APIError, response holded.Response) error {
+	if apiErr.BodySnippet != "" {
+		message = fmt.Sprintf("%s: %s", message, apiErr.BodySnippet)
+	}
+
+	details := apiErrorData{
+		StatusCode: apiErr.StatusCode,
+		Status:     apiErr.Status,
+		Message:    apiErr.Message,
+		Info:       apiErr.Info,
+		Body:       apiErr.BodySnippet,
+	}
+	if len(response.Body) > 0 {
+		details.Body = string(response.Body)
+	}
+	for _, fieldErr := range apiErr.FieldErrors {
+		details.FieldErrors = append(details.FieldErrors, fieldErrorData{Field: fieldErr.Field, Message: fieldErr.Message})
+	}
+
+	return &commandError{code: apiErrorCode(apiErr), message: message, details: details}
+}
+
+func apiErrorCode(apiErr *holded.APIError) string {
+	switch status := apiErr.StatusCode; {
+	case status == http.StatusNotFound:
+		return "NOT_FOUND"
+	case status == http.StatusUnauthorized:
+		return "UNAUTHORIZED"
+	case status == http.StatusForbidden:
+		return "FORBIDDEN"
+	case status == http.StatusTooManyRequests:
+		return "RATE_LIMITED"
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity || len(apiErr.FieldErrors) > 0:
+		return "VALIDATION_FAILED"
+	case status >= 500:
+		return "SERVER_ERROR"
+	default:
+		return "API_ERROR"
+	}
+}
+
+// recordAudit appends entry to the audit log. A failure to write it is reported on
+// stderr but does not fail the command, since the action has already run.
+func (a *App) recordAudit(cfgPath string, cfg config.Config, entry audit.Entry, runErr error) {
+	var cmdErr *commandError
+	if errors.As(runErr, &cmdErr) {
+		entry.ErrorCode = cmdErr.code
+	}
+	entry.User = currentUser(a.getenv)
+	entry.Host, _ = os.Hostname()
+
+	if err := audit.Append(cfg.AuditLogPath(cfgPath), entry); err != nil {
+		fmt.Fprintf(a.errOut, "warning: writing audit log: %v\n", err)
+	}
+}
+
+func currentUser(getenv func(string) string) string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := getenv("USER"); name != "" {
+		return name
+	}
+	return getenv("USERNAME")
+}
+
+// checkPolicy enforces read-only mode (read_only or HOLDED_READ_ONLY) and the profile's
+// allow_actions / deny_actions patterns, including the top-level allow list a profile's
+// own list narrows.
+func (a *App) checkPolicy(profile config.Profile, action actions.Action) error {
+	policy := actions.Policy{
+		ReadOnly: profile.ReadOnly || envReadOnly(a.getenv("HOLDED_READ_ONLY")),
+		Allow:    profile.AllowActions,
+		Deny:     profile.DenyActions,
+	}
+
+	err := policy.Check(action)
+	if err == nil {
+		err = actions.Policy{Allow: profile.BaseAllowActions}.Check(action)
+	}
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, actions.ErrReadOnly):
+		return &commandError{code: "READ_ONLY_MODE", message: err.Error()}
+	case errors.Is(err, actions.ErrActionNotAllowed):
+		return &commandError{code: "ACTION_NOT_ALLOWED", message: err.Error()}
+	default:
+		return &commandError{code: "CONFIG_ERROR", message: err.Error()}
+	}
+}
+
+// envReadOnly reports whether HOLDED_READ_ONLY enables read-only mode. Only an empty
+// value, 0 or false disable it: anything else that does not parse fails closed.
+func envReadOnly(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	return err != nil || enabled
+}
+
+// confirmDestructive asks before running a destructive action. Without a terminal the
+// action is refused, since --yes is the only way to confirm it.
+func (a *App) confirmDestructive(action actions.Action, req *http.Request) error {
+	target := fmt.Sprintf("%s %s", req.Method, req.URL.String())
+	refused := &commandError{
+		code:    "CONFIRMATION_REQUIRED",
+		message: fmt.Sprintf("%s is destructive (%s); re-run with --yes to confirm", action.ID, target),
+		details: confirmationData{ActionID: action.ID, Method: req.Method, URL: req.URL.String()},
+	}
+	if !a.interactive() {
+		return refused
+	}
+
+	fmt.Fprintf(a.errOut, "%s is destructive and will send:\n  %s\nContinue? [y/N]: ", action.ID, target)
+	answer, err := a.readLine()
+	if err != nil && answer == "" {
+		return refused
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		refused.message = fmt.Sprintf("%s not confirmed; nothing was sent", action.ID)
+		return refused
+	}
+}
+
+// printDryRun shows the request that actions run would send, with the API key redacted.
+func (a *App) printDryRun(action actions.Action, req *http.Request, body []byte) error {
+	data := dryRunData{
+		ActionID: action.ID,
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		Headers:  redactedHeaders(req.Header),
+		BodySize: len(body),
+	}
+	if len(body) > 0 && utf8.Valid(body) {
+		data.Body = decodeResponseBody(body)
+	}
+
+	if a.jsonOutput {
+		return a.success("actions run", "dry run: request not sent", data)
+	}
+
+	fmt.Fprintln(a.out, "# dry run: request not sent")
+	fmt.Fprintf(a.out, "%s %s\n", data.Method, data.URL)
+	for _, name := range sortedHeaderNames(data.Headers) {
+		fmt.Fprintf(a.out, "%s: %s\n", name, data.Headers[name])
+	}
+	switch {
+	case len(body) == 0:
+	case data.Body == nil:
+		fmt.Fprintf(a.out, "\n<%d bytes of binary data>\n", len(body))
+	default:
+		fmt.Fprintln(a.out)
+		fmt.Fprintln(a.out, prettyBody(body))
+	}
+
+	return nil
+}
+
+// redactedHeaders flattens headers and hides the API key.
+func redactedHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if strings.EqualFold(name, holded.APIKeyHeader) {
+			value = redactedValue
 		}
+		headers[name] = value
 	}
+	return headers
+}
 
-	catalogCtx, cancelCatalog := context.WithTimeout(context.Background(), *catalogTimeout)
-	defer cancelCatalog()
+func sortedHeaderNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
-	catalog, err := a.loadCatalog(catalogCtx, a.catalogHTTP)
+// writeOutputFile saves the file carried by the response to path.
+func writeOutputFile(path string, response holded.Response) (*outputFileData, error) {
+	payload, contentType, err := response.BinaryPayload()
 	if err != nil {
-		return &commandError{code: "CATALOG_ERROR", message: fmt.Sprintf("loading actions catalog: %v", err)}
+		return nil, &commandError{code: "INVALID_RESPONSE", message: fmt.Sprintf("extracting file from response: %v", err)}
 	}
 
-	action, err := catalog.Find(actionRef)
+	// Exports can hold customer and invoice data, so only the user may read them.
+	if err := os.WriteFile(path, payload, 0o600); err != nil {
+		return nil, &commandError{code: "FILE_WRITE_ERROR", message: fmt.Sprintf("writing output file: %v", err)}
+	}
+
+	return &outputFileData{Path: path, Size: len(payload), ContentType: contentType}, nil
+}
+
+// writeNDJSONLine writes item compacted onto a single line.
+func writeNDJSONLine(w io.Writer, item json.RawMessage) error {
+	var line bytes.Buffer
+	if err := json.Compact(&line, item); err != nil {
+		return err
+	}
+	line.WriteByte('\n')
+	_, err := w.Write(line.Bytes())
+	return err
+}
+
+// fetchAllPages follows every page of a list action and returns the concatenated items as one JSON array.
+func fetchAllPages(ctx context.Context, client *holded.Client, request holded.Request, opts holded.PageOptions) (holded.Response, holded.PageStats, error) {
+	items := make([]json.RawMessage, 0)
+	stats, err := client.ForEachPage(ctx, request, opts, func(item json.RawMessage) error {
+		items = append(items, item)
+		return nil
+	})
+
+	response := holded.Response{StatusCode: stats.StatusCode, Attempts: stats.Attempts}
 	if err != nil {
-		return &commandError{code: "ACTION_NOT_FOUND", message: err.Error()}
+		return response, stats, err
 	}
 
-	if !*skipValidation && strings.TrimSpace(*filePath) == "" {
-		if issues := actions.ValidateBodyParameters(action, requestBody); len(issues) > 0 {
-			return &commandError{
-				code:    "INVALID_BODY_PARAMS",
-				message: formatValidationIssues(issues),
-			}
+	response.Body, err = json.Marshal(items)
+	return response, stats, err
+}
+
+// activeProfile returns the profile selected by --profile, HOLDED_PROFILE or `auth use`, in that order.
+func (a *App) activeProfile(cfg config.Config) string {
+	for _, name := range []string{a.profile, a.getenv("HOLDED_PROFILE"), cfg.CurrentProfile} {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
 		}
 	}
+	return config.DefaultProfile
+}
 
-	resolvedPath, err := actions.ResolvePathTemplate(action.Path, pathParams)
+// explicitProfile reports whether the profile was chosen for this run with --profile or
+// HOLDED_PROFILE rather than by current_profile or the default.
+func (a *App) explicitProfile() bool {
+	return strings.TrimSpace(a.profile) != "" || strings.TrimSpace(a.getenv("HOLDED_PROFILE")) != ""
+}
+
+// resolveLocalCredential resolves the API key from --api-key, HOLDED_API_KEY or config.yaml
+// only. It never runs api_key_command or opens credentials.enc, so requests that are
+// printed rather than sent (--dry-run, --emit) cannot prompt for a passphrase.
+func (a *App) resolveLocalCredential(flagValue, _ string, cfg config.Config) (holded.Credential, config.Profile, error) {
+	name := a.activeProfile(cfg)
+	profile, err := cfg.Profile(name)
 	if err != nil {
-		return &usageError{message: err.Error()}
+		return holded.Credential{}, config.Profile{}, &commandError{code: "PROFILE_NOT_FOUND", message: err.Error()}
 	}
 
-	client, err := a.newClient(*baseURL, key, nil)
-	if err != nil {
-		return &commandError{code: "INVALID_BASE_URL", message: err.Error()}
+	envKey := a.getenv("HOLDED_API_KEY")
+	if strings.TrimSpace(envKey) != "" && strings.TrimSpace(flagValue) == "" && a.explicitProfile() {
+		fmt.Fprintf(a.errOut, "warning: ignoring HOLDED_API_KEY because profile %s was selected explicitly\n", name)
+		envKey = ""
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
-	defer cancel()
+	return holded.ResolveAPIKey(flagValue, envKey, profile.APIKey, name), profile, nil
+}
 
-	response, err := client.Do(ctx, holded.Request{
-		Method:  action.Method,
-		Path:    resolvedPath,
-		Query:   query,
-		Body:    requestBody,
-		Headers: headers,
-	})
+// resolveCredential resolves the API key against the active profile. Credential stores are
+// only consulted when neither --api-key nor HOLDED_API_KEY is set, so no passphrase is asked for then.
+// HOLDED_API_KEY is ignored when the profile was chosen explicitly, so --profile acme never
+// sends another company's exported key with acme's base URL and policy.
+func (a *App) resolveCredential(flagValue, cfgPath string, cfg config.Config) (holded.Credential, config.Profile, error) {
+	credential, profile, err := a.resolveLocalCredential(flagValue, cfgPath, cfg)
+	if err != nil || credential.Source == holded.CredentialSourceFlag || credential.Source == holded.CredentialSourceEnv {
+		return credential, profile, err
+	}
+	name := a.activeProfile(cfg)
+
+	var store config.CredentialStore
+	var source holded.CredentialSource
+	switch {
+	case profile.APIKeyCommand != "":
+		store, source = config.NewCommandStore(profile.APIKeyCommand), holded.CredentialSourceCommand
+	case cfg.CredentialStore == config.StoreEncrypted:
+		store, source = config.NewEncryptedFileStore(config.CredentialsPath(cfgPath), a.passphrase), holded.CredentialSourceEncrypted
+	default:
+		return credential, profile, nil
+	}
+
+	key, err := store.Get(name)
+	if errors.Is(err, config.ErrCredentialNotFound) {
+		return credential, profile, nil
+	}
 	if err != nil {
-		var apiErr *holded.APIError
-		if errors.As(err, &apiErr) {
-			message := fmt.Sprintf("action failed with status %d", apiErr.StatusCode)
-			if apiErr.BodySnippet != "" {
-				message = fmt.Sprintf("%s: %s", message, apiErr.BodySnippet)
-			}
-			return &commandError{code: "API_ERROR", message: message}
-		}
+		return holded.Credential{}, config.Profile{}, &commandError{code: "CREDENTIAL_STORE_ERROR", message: fmt.Sprintf("reading %s credentials: %v", source, err)}
+	}
+
+	return holded.Credential{Key: key, Source: source, Profile: name}, profile, nil
+}
 
-		return &commandError{code: "NETWORK_ERROR", message: fmt.Sprintf("action request failed: %v", err)}
+// passphrase returns HOLDED_PASSPHRASE or prompts for it on an interactive terminal.
+func (a *App) passphrase() (string, error) {
+	if value := a.getenv("HOLDED_PASSPHRASE"); value != "" {
+		return value, nil
+	}
+	if !a.interactive() {
+		return "", errors.New("set HOLDED_PASSPHRASE or run interactively")
 	}
 
-	decoded := decodeResponseBody(response.Body)
+	return a.readSecret("Passphrase: ")
+}
 
-	if a.jsonOutput {
-		return a.success("actions run", "action executed", actionRunData{
-			ActionID:         action.ID,
-			API:              action.API,
-			OperationID:      action.OperationID,
-			Method:           action.Method,
-			Path:             resolvedPath,
-			StatusCode:       response.StatusCode,
-			CredentialSource: string(source),
-			Response:         decoded,
-		})
+// newPassphrase is used when credentials.enc does not exist yet: an interactive passphrase
+// is asked for twice, so a typo cannot lock the keys away.
+func (a *App) newPassphrase() (string, error) {
+	first, err := a.passphrase()
+	if err != nil || a.getenv("HOLDED_PASSPHRASE") != "" {
+		return first, err
 	}
 
-	fmt.Fprintf(a.out, "%s %s -> HTTP %d\n", action.Method, resolvedPath, response.StatusCode)
-	if len(response.Body) > 0 {
-		fmt.Fprintln(a.out)
-		fmt.Fprintln(a.out, prettyBody(response.Body))
+	second, err := a.readSecret("Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if first != second {
+		return "", errors.New("passphrases do not match")
+	}
+	return first, nil
+}
+
+func (a *App) readSecret(prompt string) (string, error) {
+	fmt.Fprint(a.errOut, prompt)
+	restore := disableEcho()
+	line, err := a.readLine()
+	restore()
+	fmt.Fprintln(a.errOut)
+	if err != nil && line == "" {
+		return "", err
 	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
 
-	return nil
+// readLine reads one line from stdin through a shared buffer, so consecutive prompts
+// do not lose input buffered by an earlier one.
+func (a *App) readLine() (string, error) {
+	if a.stdinLines == nil {
+		a.stdinLines = bufio.NewReader(a.stdin)
+	}
+	return a.stdinLines.ReadString('\n')
+}
+
+// profileBaseURL prefers an explicit --base-url over the profile base_url.
+func profileBaseURL(fs *flag.FlagSet, flagValue string, profile config.Profile) string {
+	if profile.BaseURL == "" || flagPassed(fs, "base-url") {
+		return flagValue
+	}
+	return profile.BaseURL
+}
+
+func flagPassed(fs *flag.FlagSet, name string) bool {
+	passed := false
+	fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			passed = true
+		}
+	})
+	return passed
 }
 
 func (a *App) readConfig() (string, config.Config, error) {
@@ -695,6 +2174,7 @@ func (a *App) success(command, message string, data any) error {
 			Command: command,
 			Message: message,
 			Data:    data,
+			Trace:   a.traces,
 		})
 	}
 
@@ -712,9 +2192,11 @@ func (a *App) handleError(command string, err error) int {
 		errorCode = "USAGE_ERROR"
 	}
 
+	var details any
 	var cmdErr *commandError
 	if errors.As(err, &cmdErr) && cmdErr.code != "" {
 		errorCode = cmdErr.code
+		details = cmdErr.details
 	}
 
 	if a.jsonOutput {
@@ -725,7 +2207,9 @@ func (a *App) handleError(command string, err error) int {
 			Error: &jsonError{
 				Code:    errorCode,
 				Message: err.Error(),
+				Details: details,
 			},
+			Trace: a.traces,
 		})
 		return exitCode
 	}
@@ -745,19 +2229,82 @@ func (a *App) writeJSON(w io.Writer, payload jsonResponse) error {
 	return enc.Encode(payload)
 }
 
-func extractGlobalFlags(args []string) ([]string, bool) {
-	remaining := make([]string, 0, len(args))
-	jsonOutput := false
+type globalFlags struct {
+	json    bool
+	profile string
+	trace   bool
+	output  string
+	columns string
+}
 
-	for _, arg := range args {
-		if arg == "--json" {
-			jsonOutput = true
-			continue
+func extractGlobalFlags(args []string) ([]string, globalFlags, error) {
+	remaining := make([]string, 0, len(args))
+	var globals globalFlags
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--json":
+			globals.json = true
+		case arg == "--verbose" || arg == "--trace":
+			globals.trace = true
+		case arg == "--profile" || arg == "--output" || arg == "--columns":
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "-") {
+				return remaining, globals, &usageError{message: "flag needs an argument: " + arg}
+			}
+			i++
+			globals.set(arg, args[i])
+		case strings.HasPrefix(arg, "--profile="), strings.HasPrefix(arg, "--output="), strings.HasPrefix(arg, "--columns="):
+			name, value, _ := strings.Cut(arg, "=")
+			globals.set(name, value)
+		default:
+			remaining = append(remaining, arg)
 		}
-		remaining = append(remaining, arg)
 	}
 
-	return remaining, jsonOutput
+	return remaining, globals, nil
+}
+
+func (g *globalFlags) set(name, value string) {
+	switch name {
+	case "--profile":
+		g.profile = value
+	case "--output":
+		g.output = strings.ToLower(strings.TrimSpace(value))
+	case "--columns":
+		g.columns = value
+	}
+}
+
+// checkOutputFlags validates the global --output and --columns flags, which only
+// actions run and actions list support.
+func (a *App) checkOutputFlags(command string) error {
+	if a.output == "" && len(a.columns) == 0 {
+		return nil
+	}
+	if command != "actions run" && command != "actions list" {
+		return &usageError{message: "--output and --columns are only supported by actions run and actions list"}
+	}
+	if a.output == "" {
+		return &usageError{message: "--columns requires --output table|csv|tsv|yaml|json"}
+	}
+	if a.jsonOutput {
+		return &usageError{message: fmt.Sprintf("use either --json or --output %s, not both", a.output)}
+	}
+
+	supported := renderFormats
+	if command == "actions run" {
+		supported = append([]string{outputNDJSON}, renderFormats...)
+	}
+	for _, format := range supported {
+		if a.output == format {
+			if format == outputNDJSON && len(a.columns) > 0 {
+				return &usageError{message: "--columns cannot be combined with --output ndjson"}
+			}
+			return nil
+		}
+	}
+	return &usageError{message: fmt.Sprintf("unsupported --output %q; supported: %s", a.output, strings.Join(supported, ", "))}
 }
 
 func detectedCommand(args []string) string {
@@ -765,13 +2312,84 @@ func detectedCommand(args []string) string {
 		return "holded"
 	}
 
-	if (args[0] == "auth" || args[0] == "actions") && len(args) > 1 {
+	if (args[0] == "auth" || args[0] == "actions" || args[0] == "audit" || args[0] == "mock") && len(args) > 1 {
 		return args[0] + " " + args[1]
 	}
 
 	return args[0]
 }
 
+type retryFlags struct {
+	retries       *int
+	maxWait       *time.Duration
+	nonIdempotent *bool
+}
+
+func addRetryFlags(fs *flag.FlagSet, withNonIdempotent bool) retryFlags {
+	flags := retryFlags{
+		retries: fs.Int("retries", 2, "Retries for transient failures (429, 502, 503, 504, network errors)"),
+		maxWait: fs.Duration("retry-max-wait", holded.DefaultRetryMaxWait, "Maximum wait between retries"),
+	}
+	if withNonIdempotent {
+		flags.nonIdempotent = fs.Bool("retry-non-idempotent", false, "Also retry POST and PATCH requests")
+	}
+	return flags
+}
+
+func (f retryFlags) policy() holded.RetryPolicy {
+	policy := holded.RetryPolicy{
+		MaxAttempts: max(*f.retries, 0) + 1,
+		MaxWait:     *f.maxWait,
+	}
+	if f.nonIdempotent != nil {
+		policy.RetryNonIdempotent = *f.nonIdempotent
+	}
+	return policy
+}
+
+type rateLimitFlags struct {
+	rate   *float64
+	burst  *int
+	shared *bool
+}
+
+func addRateLimitFlags(fs *flag.FlagSet) rateLimitFlags {
+	return rateLimitFlags{
+		rate:   fs.Float64("rate-limit", 0, "Maximum requests per second (0 uses config rate_limit, disabled by default)"),
+		burst:  fs.Int("rate-burst", 0, "Requests allowed in a burst (default 1)"),
+		shared: fs.Bool("rate-limit-shared", false, "Share the request budget with other holded processes on this machine"),
+	}
+}
+
+// limiter merges explicitly set flags over the config rate_limit settings.
+func (f rateLimitFlags) limiter(fs *flag.FlagSet, cfg config.RateLimitConfig, configPath string) holded.RateLimiter {
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "rate-limit":
+			cfg.RequestsPerSecond = *f.rate
+		case "rate-burst":
+			cfg.Burst = *f.burst
+		case "rate-limit-shared":
+			cfg.Shared = *f.shared
+		}
+	})
+
+	if cfg.RequestsPerSecond <= 0 {
+		return nil
+	}
+	if cfg.Shared {
+		return holded.NewSharedTokenBucket(config.RateLimitStatePath(configPath), cfg.RequestsPerSecond, cfg.Burst)
+	}
+	return holded.NewTokenBucket(cfg.RequestsPerSecond, cfg.Burst)
+}
+
+func attemptsSuffix(attempts int) string {
+	if attempts <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" after %d attempts", attempts)
+}
+
 type kvValues []string
 
 func (v *kvValues) String() string {
@@ -880,6 +2498,88 @@ func decodeResponseBody(body []byte) any {
 	return trimmed
 }
 
+// outputLocation resolves the timezone of --humanize and template dates: --timezone, then
+// config.yaml, then the local timezone.
+func outputLocation(timezone string, cfg config.Config) (*time.Location, error) {
+	if name := strings.TrimSpace(timezone); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, &usageError{message: fmt.Sprintf("invalid --timezone: %v", err)}
+		}
+		return loc, nil
+	}
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, &commandError{code: "CONFIG_ERROR", message: fmt.Sprintf("invalid timezone in config: %v", err)}
+		}
+		return loc, nil
+	}
+	return time.Local, nil
+}
+
+// humanizeOptions resolves the --humanize timezone and locale: flags, then config.yaml,
+// then the local timezone and DefaultLocale.
+func humanizeOptions(timezone, locale string, cfg config.Config) (humanize.Options, error) {
+	opts := humanize.Options{Location: time.Local, Locale: humanize.DefaultLocale}
+
+	loc, err := outputLocation(timezone, cfg)
+	if err != nil {
+		return opts, err
+	}
+	opts.Location = loc
+
+	if value := strings.TrimSpace(locale); value != "" {
+		if !humanize.ValidLocale(value) {
+			return opts, &usageError{message: fmt.Sprintf("unsupported --locale %q", value)}
+		}
+		opts.Locale = value
+	} else if cfg.Locale != "" {
+		if !humanize.ValidLocale(cfg.Locale) {
+			return opts, &commandError{code: "CONFIG_ERROR", message: fmt.Sprintf("unsupported locale in config: %q", cfg.Locale)}
+		}
+		opts.Locale = cfg.Locale
+	}
+
+	return opts, nil
+}
+
+// outputTemplate parses --template/--template-file, which replace --json and --output.
+func (a *App) outputTemplate(text, file string) (*template.Template, error) {
+	tmpl, err := parseTemplate(text, file)
+	if err != nil || tmpl == nil {
+		return nil, err
+	}
+	if a.jsonOutput || a.output != "" {
+		return nil, &usageError{message: "--template cannot be combined with --json or --output"}
+	}
+	return tmpl, nil
+}
+
+func executeTemplate(w io.Writer, tmpl *template.Template, data any) error {
+	if err := tmpl.Execute(w, data); err != nil {
+		return &commandError{code: "TEMPLATE_ERROR", message: fmt.Sprintf("rendering template: %v", err)}
+	}
+	return nil
+}
+
+// writeQueryResults prints one result per line like jq -r: strings raw, other values
+// as indented JSON.
+func writeQueryResults(w io.Writer, results []any) error {
+	for _, result := range results {
+		if s, ok := result.(string); ok {
+			fmt.Fprintln(w, s)
+			continue
+		}
+		formatted, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return &commandError{code: "QUERY_ERROR", message: fmt.Sprintf("encoding --query-output result: %v", err)}
+		}
+		fmt.Fprintln(w, string(formatted))
+	}
+	return nil
+}
+
 func prettyBody(body []byte) string {
 	if len(body) == 0 {
 		return ""
@@ -948,7 +2648,29 @@ func printActionBodyItem(w io.Writer, item *actions.ActionBodyItem, indent strin
 	}
 }
 
-func formatValidationIssues(issues []actions.ValidationIssue) string {
+func printCatalogDiff(w io.Writer, diff actions.CatalogDiff) {
+	if diff.Empty() {
+		fmt.Fprintln(w, "No changes")
+		return
+	}
+
+	for _, ref := range diff.Added {
+		fmt.Fprintf(w, "+ %s %s %s\n", ref.ID, ref.Method, ref.Path)
+	}
+	for _, ref := range diff.Removed {
+		fmt.Fprintf(w, "- %s %s %s [breaking]\n", ref.ID, ref.Method, ref.Path)
+	}
+	for _, changed := range diff.Changed {
+		fmt.Fprintf(w, "~ %s\n", changed.ID)
+		for _, change := range changed.Changes {
+			fmt.Fprintf(w, "    %s\n", change)
+		}
+	}
+
+	fmt.Fprintf(w, "\nAdded: %d, removed: %d, changed: %d, breaking changes: %d\n", len(diff.Added), len(diff.Removed), len(diff.Changed), diff.Breaking)
+}
+
+func formatValidationIssues(flags string, issues []actions.ValidationIssue) string {
 	if len(issues) == 0 {
 		return ""
 	}
@@ -958,5 +2680,5 @@ func formatValidationIssues(issues []actions.ValidationIssue) string {
 		lines = append(lines, fmt.Sprintf("%s: %s", issue.Field, issue.Message))
 	}
 
-	return "invalid --body parameters: " + strings.Join(lines, "; ")
+	return fmt.Sprintf("invalid %s parameters: %s", flags, strings.Join(lines, "; "))
 }
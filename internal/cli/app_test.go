@@ -3,16 +3,24 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jaumecornado/holdedcli/internal/actions"
+	"github.com/jaumecornado/holdedcli/internal/audit"
+	"github.com/jaumecornado/holdedcli/internal/config"
 )
 
 type runResult struct {
@@ -552,3 +560,1643 @@ func TestActionsRunRejectsFileAndBodyTogether(t *testing.T) {
 		t.Fatalf("stderr = %q", res.stderr)
 	}
 }
+
+func TestActionsRefreshWritesCache(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app := NewApp(out, errOut)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	app.configPath = func() (string, error) { return cfgPath, nil }
+	app.fetchCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{
+			GeneratedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+			Source:      "https://developers.holded.com/reference/api-key",
+			Actions: []actions.Action{
+				{ID: "invoice.list-contacts", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+			},
+		}, nil
+	}
+
+	code := app.Run([]string{"actions", "refresh", "--json"})
+	if code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", code, out.String(), errOut.String())
+	}
+
+	cachePath := filepath.Join(filepath.Dir(cfgPath), "actions.json")
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("expected cache file: %v", err)
+	}
+
+	out.Reset()
+	code = app.Run([]string{"actions", "list", "--json"})
+	if code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", code, out.String(), errOut.String())
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+	data, _ := payload["data"].(map[string]any)
+	if source, _ := data["source"].(string); source != "cache:"+cachePath {
+		t.Fatalf("source = %q, want cache:%s", source, cachePath)
+	}
+	if count, _ := data["count"].(float64); count != 1 {
+		t.Fatalf("count = %v, want 1", data["count"])
+	}
+}
+
+func TestActionsListUsesEmbeddedCatalog(t *testing.T) {
+	t.Parallel()
+
+	res := runApp(t, []string{"actions", "list", "--json"}, nil)
+	if res.code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", res.code, res.stdout, res.stderr)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(res.stdout), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, res.stdout)
+	}
+	data, _ := payload["data"].(map[string]any)
+	if source, _ := data["source"].(string); !strings.HasPrefix(source, "embedded:") {
+		t.Fatalf("source = %q, want embedded prefix", source)
+	}
+}
+
+func TestActionsImportMergesOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	specPath := filepath.Join(tmp, "spec.json")
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Invoice API"},
+  "servers": [{"url": "https://api.holded.com/api/invoicing/v1"}],
+  "paths": {"/warehouses": {"get": {"operationId": "List Warehouses Patched"}}}
+}`
+	if err := os.WriteFile(specPath, []byte(spec), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app := NewApp(out, errOut)
+	cfgPath := filepath.Join(tmp, "config.yaml")
+	app.configPath = func() (string, error) { return cfgPath, nil }
+
+	code := app.Run([]string{"actions", "import", "--openapi", specPath, "--json"})
+	if code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", code, out.String(), errOut.String())
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+	data, _ := payload["data"].(map[string]any)
+	if imported, _ := data["imported"].(float64); imported != 1 {
+		t.Fatalf("imported = %v, want 1", data["imported"])
+	}
+	if count, _ := data["count"].(float64); count <= 1 {
+		t.Fatalf("count = %v, want merged catalog", data["count"])
+	}
+
+	catalog, err := actions.LoadCachedCatalog(filepath.Join(tmp, "actions.json"))
+	if err != nil {
+		t.Fatalf("LoadCachedCatalog() error = %v", err)
+	}
+	if _, err := catalog.Find("invoice.list-warehouses-patched"); err != nil {
+		t.Fatalf("expected imported action in cache: %v", err)
+	}
+	if _, err := catalog.Find("invoice.list-contacts"); err != nil {
+		t.Fatalf("expected built-in action in cache: %v", err)
+	}
+}
+
+func TestActionsDiffFailOnBreaking(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	oldPath := filepath.Join(tmp, "old.json")
+	newPath := filepath.Join(tmp, "new.json")
+	if err := actions.SaveCatalog(oldPath, actions.Catalog{Actions: []actions.Action{
+		{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+		{ID: "invoice.delete-contact", Method: "DELETE", Path: "/api/invoicing/v1/contacts/{contactId}"},
+	}}); err != nil {
+		t.Fatalf("SaveCatalog() error = %v", err)
+	}
+	if err := actions.SaveCatalog(newPath, actions.Catalog{Actions: []actions.Action{
+		{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+	}}); err != nil {
+		t.Fatalf("SaveCatalog() error = %v", err)
+	}
+
+	res := runApp(t, []string{"actions", "diff", oldPath, newPath}, nil)
+	if res.code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", res.code, res.stdout, res.stderr)
+	}
+	if !strings.Contains(res.stdout, "- invoice.delete-contact DELETE") {
+		t.Fatalf("expected removed action in output:\n%s", res.stdout)
+	}
+
+	res = runApp(t, []string{"actions", "diff", oldPath, newPath, "--fail-on-breaking", "--json"}, nil)
+	if res.code != 1 {
+		t.Fatalf("exit code = %d, want 1\nstdout=%s\nstderr=%s", res.code, res.stdout, res.stderr)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(res.stdout), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, res.stdout)
+	}
+	errorObj, _ := payload["error"].(map[string]any)
+	if code, _ := errorObj["code"].(string); code != "BREAKING_CHANGES" {
+		t.Fatalf("error.code = %q, want BREAKING_CHANGES", code)
+	}
+	details, _ := errorObj["details"].(map[string]any)
+	removed, _ := details["removed"].([]any)
+	if len(removed) != 1 {
+		t.Fatalf("expected removed action in error details: %s", res.stdout)
+	}
+}
+
+func TestActionsRunInvalidParamsValidation(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app := NewApp(out, errOut)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	app.configPath = func() (string, error) { return cfgPath, nil }
+	app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{
+			Actions: []actions.Action{
+				{
+					ID:     "invoice.list-documents",
+					API:    "Invoice API",
+					Method: "GET",
+					Path:   "/api/invoicing/v1/documents/{docType}",
+					Parameters: []actions.ActionParameter{
+						{Name: "docType", In: "path", Required: true, Type: "string", Enum: []string{"invoice", "estimate"}},
+						{Name: "page", In: "query", Type: "integer"},
+					},
+				},
+			},
+		}, nil
+	}
+
+	code := app.Run([]string{
+		"actions", "run", "invoice.list-documents",
+		"--api-key", "test-api-key",
+		"--path", "docType=bill",
+		"--query", "page=first",
+		"--json",
+	})
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1\nstdout=%s\nstderr=%s", code, out.String(), errOut.String())
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+
+	errorObj, _ := payload["error"].(map[string]any)
+	if codeValue, _ := errorObj["code"].(string); codeValue != "INVALID_PARAMS" {
+		t.Fatalf("error.code = %q, want INVALID_PARAMS", codeValue)
+	}
+	issues, _ := errorObj["details"].([]any)
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues in error details, got %s", out.String())
+	}
+}
+
+func TestPingRetriesReportAttempts(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	res := runApp(t, []string{
+		"ping",
+		"--api-key", "test-api-key",
+		"--base-url", srv.URL,
+		"--path", "/ping",
+		"--retries", "2",
+		"--retry-max-wait", "1ms",
+		"--json",
+	}, nil)
+	if res.code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", res.code, res.stdout, res.stderr)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(res.stdout), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, res.stdout)
+	}
+	data, _ := payload["data"].(map[string]any)
+	if attempts, _ := data["attempts"].(float64); attempts != 2 {
+		t.Fatalf("attempts = %v, want 2", data["attempts"])
+	}
+}
+
+func TestActionsRunAllPages(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte(`[{"id":"a"},{"id":"b"}]`))
+		case "2":
+			_, _ = w.Write([]byte(`[{"id":"c"}]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app := NewApp(out, errOut)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	app.configPath = func() (string, error) { return cfgPath, nil }
+	app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{Actions: []actions.Action{
+			{ID: "invoice.list-contacts", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+			{ID: "invoice.get-contact", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/contacts/{contactId}"},
+		}}, nil
+	}
+
+	code := app.Run([]string{
+		"actions", "run", "invoice.list-contacts",
+		"--api-key", "test-api-key",
+		"--base-url", srv.URL,
+		"--all",
+		"--json",
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", code, out.String(), errOut.String())
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+	data, _ := payload["data"].(map[string]any)
+	pagination, _ := data["pagination"].(map[string]any)
+	// The empty third page ends pagination and is not counted.
+	if pages, _ := pagination["pages"].(float64); pages != 2 {
+		t.Fatalf("pages = %v, want 2", pagination["pages"])
+	}
+	if items, _ := pagination["items"].(float64); items != 3 {
+		t.Fatalf("items = %v, want 3", pagination["items"])
+	}
+	response, _ := data["response"].([]any)
+	if len(response) != 3 {
+		t.Fatalf("expected concatenated response, got %v", data["response"])
+	}
+
+	out.Reset()
+	code = app.Run([]string{
+		"actions", "run", "invoice.get-contact",
+		"--api-key", "test-api-key",
+		"--base-url", srv.URL,
+		"--path", "contactId=a",
+		"--all",
+	})
+	if code != 2 {
+		t.Fatalf("exit code = %d, want 2 for non-list action", code)
+	}
+
+	// --page-size is rejected when the action's metadata does not declare limit.
+	app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{Actions: []actions.Action{
+			{ID: "invoice.list-contacts", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/contacts", Parameters: []actions.ActionParameter{
+				{Name: "page", In: "query", Type: "integer"},
+			}},
+		}}, nil
+	}
+	code = app.Run([]string{
+		"actions", "run", "invoice.list-contacts",
+		"--api-key", "test-api-key",
+		"--base-url", srv.URL,
+		"--all", "--page-size", "50",
+	})
+	if code != 2 {
+		t.Fatalf("exit code = %d, want 2 for --page-size without a declared limit parameter", code)
+	}
+}
+
+func TestActionsRunNDJSONStreamsPages(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte("[\n  {\"id\": \"a\"},\n  {\"id\": \"b\"}\n]"))
+		case "2":
+			_, _ = w.Write([]byte(`[{"id":"c"}]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app := NewApp(out, errOut)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	app.configPath = func() (string, error) { return cfgPath, nil }
+	app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{Actions: []actions.Action{
+			{ID: "invoice.list-contacts", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+		}}, nil
+	}
+
+	code := app.Run([]string{
+		"actions", "run", "invoice.list-contacts",
+		"--api-key", "test-api-key",
+		"--base-url", srv.URL,
+		"--all",
+		"--output", "ndjson",
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", code, out.String(), errOut.String())
+	}
+
+	want := "{\"id\":\"a\"}\n{\"id\":\"b\"}\n{\"id\":\"c\"}\n"
+	if out.String() != want {
+		t.Fatalf("stdout = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	code = app.Run([]string{
+		"actions", "run", "invoice.list-contacts",
+		"--api-key", "test-api-key",
+		"--base-url", srv.URL,
+		"--output", "ndjson",
+		"--json",
+	})
+	if code != 2 {
+		t.Fatalf("exit code = %d, want 2 for --json with --output ndjson", code)
+	}
+}
+
+func TestActionsRunOutputFileDecodesPDF(t *testing.T) {
+	t.Parallel()
+
+	pdf := []byte("%PDF-1.4\n%holded invoice")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":1,"data":"` + base64.StdEncoding.EncodeToString(pdf) + `"}`))
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app := NewApp(out, errOut)
+	dir := t.TempDir()
+	app.configPath = func() (string, error) { return filepath.Join(dir, "config.yaml"), nil }
+	app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{Actions: []actions.Action{
+			{ID: "invoice.getdocumentpdf", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/documents/{docType}/{documentId}/pdf"},
+		}}, nil
+	}
+
+	target := filepath.Join(dir, "invoice.pdf")
+	code := app.Run([]string{
+		"actions", "run", "invoice.getdocumentpdf",
+		"--api-key", "test-api-key",
+		"--base-url", srv.URL,
+		"--path", "docType=invoice",
+		"--path", "documentId=doc-1",
+		"--output-file", target,
+		"--json",
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", code, out.String(), errOut.String())
+	}
+
+	written, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("reading output file: %v", err)
+	}
+	if !bytes.Equal(written, pdf) {
+		t.Fatalf("output file = %q, want %q", written, pdf)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("output file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+	data, _ := payload["data"].(map[string]any)
+	if _, ok := data["response"]; ok {
+		t.Fatalf("response should be omitted when writing a file: %v", data)
+	}
+	file, _ := data["output_file"].(map[string]any)
+	if file["path"] != target || file["size"] != float64(len(pdf)) || file["content_type"] != "application/pdf" {
+		t.Fatalf("unexpected output_file: %v", file)
+	}
+}
+
+func TestProfilesSelectCredentialAndBaseURL(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("key"); got != "acme-key" {
+			t.Errorf("key header = %q, want acme-key", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app := NewApp(out, errOut)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	app.configPath = func() (string, error) { return cfgPath, nil }
+	env := map[string]string{}
+	app.getenv = func(key string) string { return env[key] }
+
+	run := func(args ...string) map[string]any {
+		t.Helper()
+		out.Reset()
+		if code := app.Run(append(args, "--json")); code != 0 {
+			t.Fatalf("%v exit code = %d\nstdout=%s", args, code, out.String())
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+			t.Fatalf("invalid json output: %v\n%s", err, out.String())
+		}
+		data, _ := payload["data"].(map[string]any)
+		return data
+	}
+
+	run("auth", "set", "--api-key", "default-key")
+	if data := run("auth", "set", "--profile", "acme", "--api-key", "acme-key"); data["profile"] != "acme" {
+		t.Fatalf("auth set profile = %v, want acme", data["profile"])
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	cfg.Profiles["acme"] = config.Profile{APIKey: "acme-key", BaseURL: srv.URL}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+
+	data := run("ping", "--profile", "acme", "--path", "/ping")
+	if data["profile"] != "acme" || data["base_url"] != srv.URL {
+		t.Fatalf("ping data = %v", data)
+	}
+
+	env["HOLDED_PROFILE"] = "acme"
+	if data := run("auth", "status"); data["profile"] != "acme" || data["source"] != "config" {
+		t.Fatalf("auth status with HOLDED_PROFILE = %v", data)
+	}
+	delete(env, "HOLDED_PROFILE")
+
+	// An exported key from another company must not be sent with an explicitly chosen profile.
+	env["HOLDED_API_KEY"] = "other-company-key"
+	errOut.Reset()
+	data = run("ping", "--profile", "acme", "--path", "/ping")
+	if data["profile"] != "acme" || data["credential_source"] != "config" {
+		t.Fatalf("ping with --profile and HOLDED_API_KEY = %v", data)
+	}
+	if !strings.Contains(errOut.String(), "ignoring HOLDED_API_KEY") {
+		t.Fatalf("expected warning about HOLDED_API_KEY, stderr = %q", errOut.String())
+	}
+	env["HOLDED_PROFILE"] = "acme"
+	if data := run("auth", "status"); data["profile"] != "acme" || data["source"] != "config" {
+		t.Fatalf("auth status with HOLDED_PROFILE and HOLDED_API_KEY = %v", data)
+	}
+	delete(env, "HOLDED_PROFILE")
+	delete(env, "HOLDED_API_KEY")
+
+	run("auth", "use", "acme")
+	data = run("auth", "list")
+	if data["current"] != "acme" {
+		t.Fatalf("auth list current = %v, want acme", data["current"])
+	}
+	profiles, _ := data["profiles"].([]any)
+	if len(profiles) != 2 {
+		t.Fatalf("auth list profiles = %v", profiles)
+	}
+
+	out.Reset()
+	if code := app.Run([]string{"auth", "use", "missing", "--json"}); code != 1 {
+		t.Fatalf("auth use missing exit code = %d, want 1", code)
+	}
+	if !strings.Contains(out.String(), "PROFILE_NOT_FOUND") {
+		t.Fatalf("expected PROFILE_NOT_FOUND, got %s", out.String())
+	}
+}
+
+func TestAuthEncryptedAndCommandCredentials(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	app := NewApp(out, io.Discard)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	app.configPath = func() (string, error) { return cfgPath, nil }
+	env := map[string]string{"HOLDED_PASSPHRASE": "s3cret"}
+	app.getenv = func(key string) string { return env[key] }
+
+	status := func() map[string]any {
+		t.Helper()
+		out.Reset()
+		if code := app.Run([]string{"auth", "status", "--json"}); code != 0 {
+			t.Fatalf("auth status exit code = %d\n%s", code, out.String())
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+			t.Fatalf("invalid json output: %v\n%s", err, out.String())
+		}
+		data, _ := payload["data"].(map[string]any)
+		return data
+	}
+
+	if code := app.Run([]string{"auth", "set", "--api-key", "plain-key", "--json"}); code != 0 {
+		t.Fatalf("auth set exit code = %d", code)
+	}
+	if code := app.Run([]string{"auth", "set", "--profile", "globex", "--api-key", "globex-key", "--json"}); code != 0 {
+		t.Fatalf("auth set --profile globex exit code = %d", code)
+	}
+	out.Reset()
+	if code := app.Run([]string{"auth", "set", "--api-key", "secret-key", "--encrypt", "--json"}); code != 0 {
+		t.Fatalf("auth set --encrypt exit code = %d\n%s", code, out.String())
+	}
+	if !strings.Contains(out.String(), `"migrated": [`) || !strings.Contains(out.String(), `"globex"`) {
+		t.Fatalf("expected globex key to be reported as migrated, got %s", out.String())
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if cfg.APIKey != "" || cfg.Profiles["globex"].APIKey != "" || cfg.CredentialStore != config.StoreEncrypted {
+		t.Fatalf("expected plaintext keys removed and encrypted store enabled, got %+v", cfg)
+	}
+	if data := status(); data["source"] != "encrypted" || data["configured"] != true {
+		t.Fatalf("auth status = %v, want encrypted source", data)
+	}
+	out.Reset()
+	if code := app.Run([]string{"auth", "status", "--profile", "globex", "--json"}); code != 0 || !strings.Contains(out.String(), `"source": "encrypted"`) {
+		t.Fatalf("auth status --profile globex = %d %s, want encrypted source", code, out.String())
+	}
+
+	// auth list reports the encrypted keys without asking for the passphrase.
+	delete(env, "HOLDED_PASSPHRASE")
+	out.Reset()
+	if code := app.Run([]string{"auth", "list", "--json"}); code != 0 {
+		t.Fatalf("auth list exit code = %d\n%s", code, out.String())
+	}
+	var list struct {
+		Data authListData `json:"data"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+	if len(list.Data.Profiles) != 2 {
+		t.Fatalf("auth list profiles = %+v", list.Data.Profiles)
+	}
+	for _, profile := range list.Data.Profiles {
+		if !profile.Configured || profile.Source != "encrypted" {
+			t.Fatalf("auth list profile = %+v, want configured in the encrypted store", profile)
+		}
+	}
+
+	env["HOLDED_PASSPHRASE"] = "wrong"
+	out.Reset()
+	if code := app.Run([]string{"auth", "status", "--json"}); code != 1 || !strings.Contains(out.String(), "CREDENTIAL_STORE_ERROR") {
+		t.Fatalf("expected CREDENTIAL_STORE_ERROR with wrong passphrase, got %d %s", code, out.String())
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	cfg.APIKeyCommand = "echo command-key"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+	if data := status(); data["source"] != "command" {
+		t.Fatalf("auth status = %v, want command source", data)
+	}
+	out.Reset()
+	if code := app.Run([]string{"auth", "list"}); code != 0 || !strings.Contains(out.String(), "default\tAPI key configured (command)") {
+		t.Fatalf("auth list = %d %q, want the default profile's key from its command", code, out.String())
+	}
+}
+
+func TestAuthSetEncryptConfirmsNewPassphrase(t *testing.T) {
+	t.Parallel()
+
+	run := func(input string) (int, string, string) {
+		out := &bytes.Buffer{}
+		errOut := &bytes.Buffer{}
+		app := NewApp(out, errOut)
+		dir := t.TempDir()
+		app.configPath = func() (string, error) { return filepath.Join(dir, "config.yaml"), nil }
+		app.getenv = func(string) string { return "" }
+		app.interactive = func() bool { return true }
+		app.stdin = strings.NewReader(input)
+		code := app.Run([]string{"auth", "set", "--api-key", "secret-key", "--encrypt", "--json"})
+		_, statErr := os.Stat(filepath.Join(dir, "credentials.enc"))
+		if (code == 0) != (statErr == nil) {
+			t.Fatalf("exit code = %d but credentials.enc stat error = %v", code, statErr)
+		}
+		return code, out.String(), errOut.String()
+	}
+
+	code, stdout, stderr := run("s3cret\ns3cert\n")
+	if code != 1 || !strings.Contains(stdout, "passphrases do not match") {
+		t.Fatalf("mismatched passphrase = %d %s", code, stdout)
+	}
+	if !strings.Contains(stderr, "Confirm passphrase: ") {
+		t.Fatalf("expected confirmation prompt, stderr = %q", stderr)
+	}
+
+	if code, stdout, _ := run("s3cret\ns3cret\n"); code != 0 {
+		t.Fatalf("matching passphrase = %d %s", code, stdout)
+	}
+}
+
+func TestAuthStatusVerify(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("key") != "valid-api-key":
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.HasPrefix(r.URL.Path, "/api/accounting/"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	res := runApp(t, []string{"auth", "status", "--verify", "--base-url", srv.URL, "--json"}, map[string]string{"HOLDED_API_KEY": "valid-api-key"})
+	if res.code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s", res.code, res.stdout)
+	}
+	if strings.Contains(res.stdout, "valid-api-key") {
+		t.Fatalf("output must not contain the API key: %s", res.stdout)
+	}
+
+	var payload struct {
+		Data authStatusData `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(res.stdout), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, res.stdout)
+	}
+	data := payload.Data
+	if data.MaskedKey != "****-key" || !strings.HasPrefix(data.Fingerprint, "sha256:") {
+		t.Fatalf("unexpected key introspection: %+v", data)
+	}
+	if data.Verification == nil || data.Verification.Status != "valid" || len(data.Verification.APIs) != 5 {
+		t.Fatalf("unexpected verification: %+v", data.Verification)
+	}
+	for _, access := range data.Verification.APIs {
+		if want := access.API != "Accounting API"; access.Accessible != want {
+			t.Fatalf("%s accessible = %v, want %v", access.API, access.Accessible, want)
+		}
+	}
+
+	res = runApp(t, []string{"auth", "status", "--verify", "--base-url", srv.URL, "--json"}, map[string]string{"HOLDED_API_KEY": "revoked-api-key"})
+	if res.code != 1 || !strings.Contains(res.stdout, "INVALID_API_KEY") || !strings.Contains(res.stdout, `"status": "invalid"`) {
+		t.Fatalf("expected INVALID_API_KEY with details, got %d %s", res.code, res.stdout)
+	}
+}
+
+func TestActionsRunWarnsWithoutCatalogMetadata(t *testing.T) {
+	t.Parallel()
+
+	// The embedded snapshot has no body schemas, so the body is sent unvalidated with a warning.
+	result := runApp(t, []string{"actions", "run", "invoice.create-contact", "--api-key", "k", "--body", `{"name":"Acme"}`, "--dry-run"}, nil)
+	if result.code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s\nstderr=%s", result.code, result.stdout, result.stderr)
+	}
+	if !strings.Contains(result.stderr, "warning: invoice.create-contact has no parameter or body metadata") {
+		t.Fatalf("missing metadata warning, stderr = %q", result.stderr)
+	}
+
+	result = runApp(t, []string{"actions", "run", "invoice.create-contact", "--api-key", "k", "--body", `{"name":"Acme"}`, "--dry-run", "--skip-validation"}, nil)
+	if result.code != 0 || strings.Contains(result.stderr, "warning:") {
+		t.Fatalf("exit code = %d, stderr = %q", result.code, result.stderr)
+	}
+}
+
+func TestActionsRunDryRun(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("dry run must not contact the API, got %s %s", r.Method, r.URL)
+	}))
+	defer srv.Close()
+
+	catalog := func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{Actions: []actions.Action{{
+			ID:     "invoice.update-contact",
+			API:    "Invoice API",
+			Method: "PUT",
+			Path:   "/api/invoicing/v1/contacts/{contactId}",
+			RequestBody: &actions.ActionRequestBody{Fields: []actions.ActionBodyField{
+				{Name: "name", Type: "string"},
+			}},
+		}}}, nil
+	}
+
+	out := &bytes.Buffer{}
+	app := NewApp(out, io.Discard)
+	app.configPath = func() (string, error) { return filepath.Join(t.TempDir(), "config.yaml"), nil }
+	app.loadCatalog = catalog
+
+	code := app.Run([]string{
+		"actions", "run", "invoice.update-contact",
+		"--api-key", "secret-api-key",
+		"--base-url", srv.URL,
+		"--path", "contactId=c1",
+		"--query", "lang=es",
+		"--body", `{"name":"Acme"}`,
+		"--dry-run",
+		"--json",
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s", code, out.String())
+	}
+	if strings.Contains(out.String(), "secret-api-key") {
+		t.Fatalf("dry run output leaks the API key: %s", out.String())
+	}
+
+	var payload struct {
+		Data dryRunData `json:"data"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+	data := payload.Data
+	if data.Method != "PUT" || data.URL != srv.URL+"/api/invoicing/v1/contacts/c1?lang=es" {
+		t.Fatalf("unexpected request line: %s %s", data.Method, data.URL)
+	}
+	if data.Headers["Key"] != "<redacted>" || data.Headers["Content-Type"] != "application/json" {
+		t.Fatalf("unexpected headers: %v", data.Headers)
+	}
+	if body, _ := data.Body.(map[string]any); body["name"] != "Acme" {
+		t.Fatalf("unexpected body: %v", data.Body)
+	}
+
+	out.Reset()
+	code = app.Run([]string{
+		"actions", "run", "invoice.update-contact",
+		"--base-url", srv.URL,
+		"--path", "contactId=c1",
+		"--body", `{"name":"Acme"}`,
+		"--dry-run",
+	})
+	if code != 0 {
+		t.Fatalf("text dry run exit code = %d\nstdout=%s", code, out.String())
+	}
+	if !strings.Contains(out.String(), "PUT "+srv.URL+"/api/invoicing/v1/contacts/c1\n") || !strings.Contains(out.String(), "Key: <redacted>") {
+		t.Fatalf("unexpected text dry run output:\n%s", out.String())
+	}
+}
+
+func TestActionsRunDryRunSkipsCredentialStores(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("api_key_command uses sh")
+	}
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	marker := filepath.Join(dir, "command-ran")
+	if err := config.Save(cfgPath, config.Config{
+		CredentialStore: config.StoreEncrypted,
+		Profiles: map[string]config.Profile{
+			"vault": {APIKeyCommand: "touch " + marker + " && echo vault-key"},
+		},
+	}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+	// An unreadable store would fail with CREDENTIAL_STORE_ERROR if it were opened.
+	if err := os.WriteFile(config.CredentialsPath(cfgPath), []byte("corrupt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (int, string) {
+		out := &bytes.Buffer{}
+		app := NewApp(out, io.Discard)
+		app.configPath = func() (string, error) { return cfgPath, nil }
+		app.getenv = func(string) string { return "" }
+		app.interactive = func() bool { return false }
+		app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+			return actions.Catalog{Actions: []actions.Action{
+				{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+			}}, nil
+		}
+		code := app.Run(append([]string{"actions", "run", "invoice.list-contacts"}, args...))
+		return code, out.String()
+	}
+
+	if code, out := run("--dry-run"); code != 0 || !strings.Contains(out, "Key: <redacted>") {
+		t.Fatalf("dry run with encrypted store = %d: %s", code, out)
+	}
+	if code, out := run("--emit", "curl", "--profile", "vault"); code != 0 || !strings.Contains(out, "$HOLDED_API_KEY") {
+		t.Fatalf("emit with api_key_command = %d: %s", code, out)
+	}
+	if _, err := os.Stat(marker); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("api_key_command ran during --emit: %v", err)
+	}
+	if code, out := run("--json"); code != 1 || !strings.Contains(out, "CREDENTIAL_STORE_ERROR") {
+		t.Fatalf("real run = %d: %s, want the store to be read", code, out)
+	}
+}
+
+func TestActionsRunEmitMultipartCurl(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	attachment := filepath.Join(dir, "ticket.jpg")
+	if err := os.WriteFile(attachment, []byte{0xff, 0xd8, 0xff}, 0o600); err != nil {
+		t.Fatalf("writing attachment: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	app := NewApp(out, io.Discard)
+	app.configPath = func() (string, error) { return filepath.Join(dir, "config.yaml"), nil }
+	app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{Actions: []actions.Action{{
+			ID:     "invoice.attach-file",
+			API:    "Invoice API",
+			Method: "POST",
+			Path:   "/api/invoicing/v1/documents/{docType}/{documentId}/attach",
+		}}}, nil
+	}
+
+	code := app.Run([]string{
+		"actions", "run", "invoice.attach-file",
+		"--api-key", "secret-api-key",
+		"--path", "docType=purchase",
+		"--path", "documentId=d1",
+		"--file", attachment,
+		"--emit", "curl",
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d\nstdout=%s", code, out.String())
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"curl -X POST 'https://api.holded.com/api/invoicing/v1/documents/purchase/d1/attach'",
+		`-H "key: $HOLDED_API_KEY"`,
+		"-F 'file=@" + attachment + "'",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "secret-api-key") || strings.Contains(got, "multipart/form-data") {
+		t.Fatalf("unexpected key or multipart content type in:\n%s", got)
+	}
+}
+
+func TestActionsRunDestructiveConfirmation(t *testing.T) {
+	t.Parallel()
+
+	var deletes int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deletes++
+		_, _ = w.Write([]byte(`{"status":1}`))
+	}))
+	defer srv.Close()
+
+	newApp := func(interactive bool, input string) (*App, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		app := NewApp(out, io.Discard)
+		app.configPath = func() (string, error) { return filepath.Join(t.TempDir(), "config.yaml"), nil }
+		app.interactive = func() bool { return interactive }
+		app.stdin = strings.NewReader(input)
+		app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+			return actions.Catalog{Actions: []actions.Action{
+				{ID: "invoice.delete-contact", API: "Invoice API", Method: "DELETE", Path: "/api/invoicing/v1/contacts/{contactId}"},
+			}}, nil
+		}
+		return app, out
+	}
+	args := []string{
+		"actions", "run", "invoice.delete-contact",
+		"--api-key", "test-api-key",
+		"--base-url", srv.URL,
+		"--path", "contactId=c1",
+		"--json",
+	}
+
+	app, out := newApp(false, "")
+	if code := app.Run(args); code != 1 || !strings.Contains(out.String(), "CONFIRMATION_REQUIRED") {
+		t.Fatalf("non-interactive run: code = %d, output = %s", code, out.String())
+	}
+	if !strings.Contains(out.String(), srv.URL+"/api/invoicing/v1/contacts/c1") {
+		t.Fatalf("refusal should show the resolved target: %s", out.String())
+	}
+
+	app, out = newApp(true, "n\n")
+	if code := app.Run(args); code != 1 || !strings.Contains(out.String(), "CONFIRMATION_REQUIRED") {
+		t.Fatalf("declined run: code = %d, output = %s", code, out.String())
+	}
+	if deletes != 0 {
+		t.Fatalf("refused runs must not contact the API, got %d requests", deletes)
+	}
+
+	app, out = newApp(true, "y\n")
+	if code := app.Run(args); code != 0 {
+		t.Fatalf("confirmed run: code = %d, output = %s", code, out.String())
+	}
+
+	app, out = newApp(false, "")
+	if code := app.Run(append(args, "--yes")); code != 0 {
+		t.Fatalf("--yes run: code = %d, output = %s", code, out.String())
+	}
+	if deletes != 2 {
+		t.Fatalf("deletes = %d, want 2", deletes)
+	}
+}
+
+func TestActionsRunReadOnlyAndActionPatterns(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s request in read-only mode", r.Method)
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := config.Save(cfgPath, config.Config{
+		APIKey:       "default-key",
+		AllowActions: []string{"invoice.list-*", "invoice.create-*"},
+		Profiles: map[string]config.Profile{
+			"analyst": {APIKey: "analyst-key", AllowActions: []string{"invoice.list-*"}},
+			"agent":   {APIKey: "agent-key", AllowActions: []string{"*"}},
+		},
+	}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+
+	run := func(env map[string]string, args ...string) (int, string) {
+		out := &bytes.Buffer{}
+		app := NewApp(out, io.Discard)
+		app.configPath = func() (string, error) { return cfgPath, nil }
+		app.getenv = func(key string) string { return env[key] }
+		app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+			return actions.Catalog{Actions: []actions.Action{
+				{ID: "invoice.list-contacts", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+				{ID: "invoice.get-contact", API: "Invoice API", Method: "GET", Path: "/api/invoicing/v1/contacts/{contactId}"},
+				{ID: "invoice.create-contact", API: "Invoice API", Method: "POST", Path: "/api/invoicing/v1/contacts"},
+			}}, nil
+		}
+		code := app.Run(append([]string{"actions", "run"}, append(args, "--base-url", srv.URL, "--json")...))
+		return code, out.String()
+	}
+
+	readOnly := map[string]string{"HOLDED_READ_ONLY": "1"}
+	if code, out := run(readOnly, "invoice.create-contact", "--body", `{}`); code != 1 || !strings.Contains(out, "READ_ONLY_MODE") {
+		t.Fatalf("read-only POST: code = %d, output = %s", code, out)
+	}
+	if code, out := run(readOnly, "invoice.list-contacts"); code != 0 {
+		t.Fatalf("read-only GET: code = %d, output = %s", code, out)
+	}
+	for _, value := range []string{"yes", "on", "TRUE"} {
+		if code, out := run(map[string]string{"HOLDED_READ_ONLY": value}, "invoice.create-contact", "--body", `{}`); code != 1 || !strings.Contains(out, "READ_ONLY_MODE") {
+			t.Fatalf("HOLDED_READ_ONLY=%s POST: code = %d, output = %s", value, code, out)
+		}
+	}
+
+	if code, out := run(nil, "invoice.get-contact", "--profile", "analyst", "--path", "contactId=c1"); code != 1 || !strings.Contains(out, "ACTION_NOT_ALLOWED") {
+		t.Fatalf("action outside allow list: code = %d, output = %s", code, out)
+	}
+	if code, out := run(nil, "invoice.list-contacts", "--profile", "analyst"); code != 0 {
+		t.Fatalf("allowed action: code = %d, output = %s", code, out)
+	}
+
+	// A profile's allow list narrows the top-level one and never widens it.
+	if code, out := run(nil, "invoice.get-contact", "--profile", "agent", "--path", "contactId=c1"); code != 1 || !strings.Contains(out, "ACTION_NOT_ALLOWED") {
+		t.Fatalf("profile widening top-level allow list: code = %d, output = %s", code, out)
+	}
+	if code, out := run(nil, "invoice.list-contacts", "--profile", "agent"); code != 0 {
+		t.Fatalf("action in both allow lists: code = %d, output = %s", code, out)
+	}
+}
+
+func TestActionsRunWritesAuditLog(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/invoicing/v1/contacts/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"c1"}`))
+	}))
+	defer srv.Close()
+
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	newApp := func() (*App, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		app := NewApp(out, io.Discard)
+		app.configPath = func() (string, error) { return cfgPath, nil }
+		app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+			return actions.Catalog{Actions: []actions.Action{
+				{ID: "invoice.get-contact", Method: "GET", Path: "/api/invoicing/v1/contacts/{contactId}"},
+				{ID: "invoice.create-contact", Method: "POST", Path: "/api/invoicing/v1/contacts"},
+			}}, nil
+		}
+		return app, out
+	}
+
+	app, _ := newApp()
+	if code := app.Run([]string{"actions", "run", "invoice.get-contact", "--api-key", "k", "--base-url", srv.URL, "--path", "contactId=c1", "--query", "include=addresses"}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	app, _ = newApp()
+	if code := app.Run([]string{"actions", "run", "invoice.get-contact", "--api-key", "k", "--base-url", srv.URL, "--path", "contactId=missing", "--retries", "0"}); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	app, _ = newApp()
+	if code := app.Run([]string{"actions", "run", "invoice.create-contact", "--api-key", "k", "--base-url", srv.URL, "--body", `{"name":"Acme"}`, "--skip-validation"}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	// Dry runs never reach the API and are not audited.
+	app, out := newApp()
+	if code := app.Run([]string{"actions", "run", "invoice.create-contact", "--base-url", srv.URL, "--body", `{}`, "--skip-validation", "--dry-run"}); code != 0 {
+		t.Fatalf("exit code = %d\n%s", code, out.String())
+	}
+
+	app, out = newApp()
+	if code := app.Run([]string{"audit", "list", "--since", "1h", "--action", "invoice.get-*", "--json"}); code != 0 {
+		t.Fatalf("exit code = %d\n%s", code, out.String())
+	}
+
+	var payload struct {
+		Data struct {
+			LogPath string        `json:"log_path"`
+			Entries []audit.Entry `json:"entries"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+	if payload.Data.LogPath != filepath.Join(filepath.Dir(cfgPath), "audit.jsonl") {
+		t.Fatalf("log_path = %q", payload.Data.LogPath)
+	}
+	entries := payload.Data.Entries
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want 2", entries)
+	}
+	if entries[0].Path != "/api/invoicing/v1/contacts/c1" || entries[0].StatusCode != 200 || entries[0].CredentialSource != "flag" {
+		t.Fatalf("first entry = %+v", entries[0])
+	}
+	if got := entries[0].Query["include"]; len(got) != 1 || got[0] != "addresses" {
+		t.Fatalf("query = %v", entries[0].Query)
+	}
+	if entries[1].StatusCode != 404 || entries[1].ErrorCode != "NOT_FOUND" {
+		t.Fatalf("second entry = %+v", entries[1])
+	}
+
+	all, err := audit.Read(filepath.Join(filepath.Dir(cfgPath), "audit.jsonl"), audit.Filter{})
+	if err != nil {
+		t.Fatalf("audit.Read() error = %v", err)
+	}
+	if len(all) != 3 || all[2].BodySHA256 != audit.HashBody([]byte(`{"name":"Acme"}`)) {
+		t.Fatalf("entries = %+v", all)
+	}
+
+	app, _ = newApp()
+	if code := app.Run([]string{"audit", "list", "--since", "yesterday"}); code != 2 {
+		t.Fatalf("exit code = %d, want 2 for invalid --since", code)
+	}
+}
+
+func TestActionsRunTraceLogsAttempts(t *testing.T) {
+	t.Parallel()
+
+	errorBody := `{"info":"` + strings.Repeat("invalid ", 40) + `"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(errorBody))
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app := NewApp(out, errOut)
+	app.configPath = func() (string, error) { return filepath.Join(t.TempDir(), "config.yaml"), nil }
+	app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{Actions: []actions.Action{
+			{ID: "invoice.get-contact", Method: "GET", Path: "/api/invoicing/v1/contacts/{contactId}"},
+		}}, nil
+	}
+
+	code := app.Run([]string{"actions", "run", "invoice.get-contact", "--api-key", "secret-api-key-9876", "--base-url", srv.URL, "--path", "contactId=c1", "--verbose", "--json"})
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+
+	logged := errOut.String()
+	for _, want := range []string{"> GET " + srv.URL + "/api/invoicing/v1/contacts/c1", "> Key: ****9876", "< 400 Bad Request", "* timing: dns=", errorBody} {
+		if !strings.Contains(logged, want) {
+			t.Fatalf("stderr missing %q:\n%s", want, logged)
+		}
+	}
+	if strings.Contains(logged, "secret-api-key") || strings.Contains(out.String(), "secret-api-key") {
+		t.Fatal("trace leaks the API key")
+	}
+
+	var payload struct {
+		Trace []traceData `json:"trace"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+	if len(payload.Trace) != 1 {
+		t.Fatalf("trace = %+v, want one attempt", payload.Trace)
+	}
+	if trace := payload.Trace[0]; trace.StatusCode != 400 || trace.ErrorBody != errorBody || trace.Timing.TotalMS <= 0 {
+		t.Fatalf("trace = %+v", trace)
+	}
+}
+
+func TestActionsRunMapsAPIErrorCodes(t *testing.T) {
+	t.Parallel()
+
+	responses := map[string]struct {
+		status int
+		body   string
+	}{
+		"missing":   {http.StatusNotFound, `{"status":0,"info":"Contact not found"}`},
+		"denied":    {http.StatusUnauthorized, `{"message":"Invalid API key"}`},
+		"forbidden": {http.StatusForbidden, `{"message":"Access denied"}`},
+		"busy":      {http.StatusTooManyRequests, `{}`},
+		"invalid":   {http.StatusBadRequest, `{"message":"Validation failed","errors":{"email":"is invalid"}}`},
+		"broken":    {http.StatusInternalServerError, `oops`},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := responses[path.Base(r.URL.Path)]
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+	}))
+	defer srv.Close()
+
+	run := func(contactID string) (int, map[string]any) {
+		out := &bytes.Buffer{}
+		app := NewApp(out, io.Discard)
+		app.configPath = func() (string, error) { return filepath.Join(t.TempDir(), "config.yaml"), nil }
+		app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+			return actions.Catalog{Actions: []actions.Action{
+				{ID: "invoice.get-contact", Method: "GET", Path: "/api/invoicing/v1/contacts/{contactId}"},
+			}}, nil
+		}
+		code := app.Run([]string{"actions", "run", "invoice.get-contact", "--api-key", "k", "--base-url", srv.URL, "--path", "contactId=" + contactID, "--retries", "0", "--json"})
+
+		var payload struct {
+			Error map[string]any `json:"error"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+			t.Fatalf("invalid json output: %v\n%s", err, out.String())
+		}
+		return code, payload.Error
+	}
+
+	for contactID, want := range map[string]string{
+		"missing":   "NOT_FOUND",
+		"denied":    "UNAUTHORIZED",
+		"forbidden": "FORBIDDEN",
+		"busy":      "RATE_LIMITED",
+		"invalid":   "VALIDATION_FAILED",
+		"broken":    "SERVER_ERROR",
+	} {
+		code, jsonErr := run(contactID)
+		if code != 1 || jsonErr["code"] != want {
+			t.Fatalf("%s: exit code = %d, error = %v, want %s", contactID, code, jsonErr, want)
+		}
+	}
+
+	_, jsonErr := run("invalid")
+	details, _ := jsonErr["details"].(map[string]any)
+	fields, _ := details["field_errors"].([]any)
+	if details["message"] != "Validation failed" || len(fields) != 1 {
+		t.Fatalf("details = %v", details)
+	}
+	if field, _ := fields[0].(map[string]any); field["field"] != "email" || field["message"] != "is invalid" {
+		t.Fatalf("field_errors = %v", fields)
+	}
+
+	_, jsonErr = run("missing")
+	details, _ = jsonErr["details"].(map[string]any)
+	if details["status_code"] != float64(404) || details["status"] != "0" || details["info"] != "Contact not found" {
+		t.Fatalf("details = %v", details)
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"d1","contact":{"name":"Acme"},"total":121},{"id":"d2","contact":{"name":"Globex"},"total":10}]`))
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	app := NewApp(out, io.Discard)
+	app.configPath = func() (string, error) { return filepath.Join(t.TempDir(), "config.yaml"), nil }
+	app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{Actions: []actions.Action{
+			{ID: "invoice.list-documents", Method: "GET", Path: "/api/invoicing/v1/documents/{docType}"},
+		}}, nil
+	}
+	code := app.Run([]string{"actions", "run", "invoice.list-documents", "--api-key", "k", "--base-url", srv.URL, "--path", "docType=invoice", "--output", "table", "--columns", "id,contact.name,total"})
+	if code != 0 {
+		t.Fatalf("exit code = %d\n%s", code, out.String())
+	}
+	if want := "id  contact.name  total\nd1  Acme          121\nd2  Globex        10\n"; out.String() != want {
+		t.Fatalf("table output =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	res := runApp(t, []string{"actions", "list", "--filter", "invoice.get-contact", "--output=csv", "--columns=id,method"}, nil)
+	if res.code != 0 {
+		t.Fatalf("exit code = %d\nstderr=%s", res.code, res.stderr)
+	}
+	if !strings.HasPrefix(res.stdout, "id,method\n") || !strings.Contains(res.stdout, "\ninvoice.get-contact,GET\n") {
+		t.Fatalf("csv output = %q", res.stdout)
+	}
+
+	for _, args := range [][]string{
+		{"ping", "--output", "table"},
+		{"actions", "list", "--columns", "id"},
+		{"actions", "list", "--output", "xml"},
+		{"actions", "list", "--output", "ndjson"},
+		{"actions", "list", "--output", "yaml", "--json"},
+	} {
+		if res := runApp(t, args, nil); res.code != 2 {
+			t.Fatalf("%v: exit code = %d, want 2", args, res.code)
+		}
+	}
+}
+
+func TestActionsRunQueryOutput(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"d1","total":121,"contact":{"name":"Acme"}},{"id":"d2","total":10,"contact":{"name":"Globex"}}]`))
+	}))
+	defer srv.Close()
+
+	run := func(args ...string) (int, string) {
+		out := &bytes.Buffer{}
+		app := NewApp(out, io.Discard)
+		app.configPath = func() (string, error) { return filepath.Join(t.TempDir(), "config.yaml"), nil }
+		app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+			return actions.Catalog{Actions: []actions.Action{
+				{ID: "invoice.list-documents", Method: "GET", Path: "/api/invoicing/v1/documents/{docType}"},
+			}}, nil
+		}
+		base := []string{"actions", "run", "invoice.list-documents", "--api-key", "k", "--base-url", srv.URL, "--path", "docType=invoice"}
+		code := app.Run(append(base, args...))
+		return code, out.String()
+	}
+
+	code, out := run("--query-output", ".[].id")
+	if code != 0 || out != "d1\nd2\n" {
+		t.Fatalf("exit code = %d, output = %q", code, out)
+	}
+
+	code, out = run("--query-output", ".[] | select(.total > 100) | {id, name: .contact.name}", "--json")
+	if code != 0 {
+		t.Fatalf("exit code = %d\n%s", code, out)
+	}
+	var payload struct {
+		Data struct {
+			Response []map[string]any `json:"response"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out)
+	}
+	if got := payload.Data.Response; len(got) != 1 || got[0]["id"] != "d1" || got[0]["name"] != "Acme" {
+		t.Fatalf("response = %v", got)
+	}
+
+	code, out = run("--query-output", ".[] | {id, total}", "--output", "csv")
+	if code != 0 || out != "id,total\nd1,121\nd2,10\n" {
+		t.Fatalf("exit code = %d, output = %q", code, out)
+	}
+
+	// A single result is still wrapped, so the JSON shape does not depend on the data.
+	code, out = run("--query-output", "length", "--output", "json")
+	if code != 0 || strings.Join(strings.Fields(out), "") != "[2]" {
+		t.Fatalf("exit code = %d, output = %q", code, out)
+	}
+	code, out = run("--query-output", ".[] | select(.total > 1000)", "--json")
+	if code != 0 || !strings.Contains(out, `"response": []`) {
+		t.Fatalf("exit code = %d, output = %s", code, out)
+	}
+
+	if code, _ := run("--query-output", ".[", "--json"); code != 2 {
+		t.Fatalf("exit code = %d, want 2 for an invalid expression", code)
+	}
+	if code, out := run("--query-output", ".[].id.name", "--json"); code != 1 || !strings.Contains(out, "QUERY_ERROR") {
+		t.Fatalf("exit code = %d, output = %s", code, out)
+	}
+}
+
+func TestTemplateOutput(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"docNumber":"F001","total":1210.5},{"docNumber":"F002","total":10}]`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "report.tmpl")
+	if err := os.WriteFile(templatePath, []byte("{{range .}}{{.docNumber}}: {{money .total}}\n{{end}}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	app := NewApp(out, io.Discard)
+	app.configPath = func() (string, error) { return filepath.Join(dir, "config.yaml"), nil }
+	app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+		return actions.Catalog{Actions: []actions.Action{
+			{ID: "invoice.list-documents", Method: "GET", Path: "/api/invoicing/v1/documents/{docType}"},
+		}}, nil
+	}
+	code := app.Run([]string{"actions", "run", "invoice.list-documents", "--api-key", "k", "--base-url", srv.URL, "--path", "docType=invoice", "--template-file", templatePath})
+	if code != 0 || out.String() != "F001: 1,210.50\nF002: 10.00\n" {
+		t.Fatalf("exit code = %d, output = %q", code, out.String())
+	}
+
+	res := runApp(t, []string{"actions", "list", "--filter", "invoice.get-contact", "--template", `{{range .}}{{if eq .id "invoice.get-contact"}}{{.method}} {{.path}}{{end}}{{end}}`}, nil)
+	if res.code != 0 || res.stdout != "GET /api/invoicing/v1/contacts/{contactId}" {
+		t.Fatalf("exit code = %d, output = %q, stderr = %s", res.code, res.stdout, res.stderr)
+	}
+
+	for _, args := range [][]string{
+		{"actions", "list", "--template", "{{.", "--json"},
+		{"actions", "list", "--template", "{{.}}", "--json"},
+		{"actions", "list", "--template", "{{.}}", "--output", "csv"},
+	} {
+		if res := runApp(t, args, nil); res.code != 2 {
+			t.Fatalf("%v: exit code = %d, want 2", args, res.code)
+		}
+	}
+}
+
+func TestActionsRunHumanize(t *testing.T) {
+	t.Parallel()
+
+	var sentBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			sentBody, _ = io.ReadAll(r.Body)
+			_, _ = w.Write([]byte(`{"status":1,"id":"d1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":"d1","date":1790805600,"currency":"eur","total":1210.5}]`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	if err := config.Save(cfgPath, config.Config{Timezone: "Europe/Madrid", Locale: "es"}); err != nil {
+		t.Fatal(err)
+	}
+	run := func(actionID string, args ...string) (int, string) {
+		out := &bytes.Buffer{}
+		app := NewApp(out, out)
+		app.configPath = func() (string, error) { return cfgPath, nil }
+		app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+			return actions.Catalog{Actions: []actions.Action{
+				{ID: "invoice.list-documents", Method: "GET", Path: "/api/invoicing/v1/documents/{docType}"},
+				{ID: "invoice.create-document", Method: "POST", Path: "/api/invoicing/v1/documents/{docType}", RequestBody: &actions.ActionRequestBody{
+					Fields: []actions.ActionBodyField{
+						{Name: "contactId", Type: "string", Required: true},
+						{Name: "date", Type: "integer", Required: true},
+					},
+				}},
+			}}, nil
+		}
+		code := app.Run(append([]string{"actions", "run", actionID, "--api-key", "k", "--base-url", srv.URL, "--path", "docType=invoice"}, args...))
+		return code, out.String()
+	}
+
+	code, out := run("invoice.list-documents", "--humanize", "--output", "csv", "--columns", "id,date,total")
+	if code != 0 || out != "id,date,total\nd1,2026-10-01,\"1.210,50 EUR\"\n" {
+		t.Fatalf("exit code = %d, output = %q", code, out)
+	}
+
+	// Fields are humanized in the query result, so filters still compare raw values.
+	code, out = run("invoice.list-documents", "--humanize", "--timezone", "UTC", "--locale", "en", "--query-output", ".[] | select(.total > 1000) | {date, total, currency}")
+	if code != 0 || !strings.Contains(out, `"date": "2026-09-30T22:00:00Z"`) || !strings.Contains(out, `"total": "1,210.50 EUR"`) {
+		t.Fatalf("exit code = %d, output = %q", code, out)
+	}
+
+	// Template dates use the same timezone as --humanize: config.yaml, then --timezone.
+	dateTemplate := `{{range .}}{{date .date "2006-01-02 15:04"}}{{end}}`
+	if code, out := run("invoice.list-documents", "--template", dateTemplate); code != 0 || out != "2026-10-01 00:00" {
+		t.Fatalf("template date with config timezone = %d, %q", code, out)
+	}
+	if code, out := run("invoice.list-documents", "--template", dateTemplate, "--timezone", "UTC"); code != 0 || out != "2026-09-30 22:00" {
+		t.Fatalf("template date with --timezone = %d, %q", code, out)
+	}
+
+	// Without --humanize the ISO date fails validation; with it, it is sent as epoch seconds.
+	if code, out := run("invoice.create-document", "--body", `{"contactId":"c1","date":"2026-10-01"}`); code != 1 || !strings.Contains(out, "date") {
+		t.Fatalf("exit code = %d, output = %s", code, out)
+	}
+	if code, out := run("invoice.create-document", "--humanize", "--body", `{"contactId":"c1","date":"2026-10-01"}`); code != 0 {
+		t.Fatalf("exit code = %d, output = %s", code, out)
+	}
+	if string(sentBody) != `{"contactId":"c1","date":1790805600}` {
+		t.Fatalf("sent body = %s", sentBody)
+	}
+
+	if code, _ := run("invoice.list-documents", "--timezone", "UTC"); code != 2 {
+		t.Fatalf("exit code = %d, want 2 for --timezone without --humanize", code)
+	}
+	if code, _ := run("invoice.list-documents", "--humanize", "--timezone", "Mars/Olympus"); code != 2 {
+		t.Fatalf("exit code = %d, want 2 for an unknown timezone", code)
+	}
+}
+
+func TestMockServe(t *testing.T) {
+	t.Parallel()
+
+	catalog := actions.Catalog{Actions: []actions.Action{
+		{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+		{ID: "invoice.create-contact", Method: "POST", Path: "/api/invoicing/v1/contacts", RequestBody: &actions.ActionRequestBody{
+			Fields: []actions.ActionBodyField{{Name: "name", Type: "string", Required: true}},
+		}},
+		{ID: "invoice.get-contact", Method: "GET", Path: "/api/invoicing/v1/contacts/{contactId}"},
+	}}
+	loadCatalog := func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) { return catalog, nil }
+
+	out := &bytes.Buffer{}
+	logs := &bytes.Buffer{}
+	app := NewApp(out, logs)
+	app.configPath = func() (string, error) { return filepath.Join(t.TempDir(), "config.yaml"), nil }
+	app.getenv = func(string) string { return "" }
+	app.loadCatalog = loadCatalog
+	addrs := make(chan string, 1)
+	app.listen = func(network, address string) (net.Listener, error) {
+		if address != "127.0.0.1:0" {
+			t.Errorf("listen address = %q", address)
+		}
+		listener, err := net.Listen(network, address)
+		if err == nil {
+			addrs <- listener.Addr().String()
+		}
+		return listener, err
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	app.notifyContext = func() (context.Context, context.CancelFunc) { return ctx, stop }
+
+	done := make(chan int, 1)
+	go func() { done <- app.Run([]string{"mock", "serve", "--port", "0", "--api-key", "mock-key"}) }()
+	var baseURL string
+	select {
+	case addr := <-addrs:
+		baseURL = "http://" + addr
+	case code := <-done:
+		t.Fatalf("mock serve exited early with %d: %s", code, logs.String())
+	}
+
+	run := func(args ...string) (int, string) {
+		out := &bytes.Buffer{}
+		client := NewApp(out, out)
+		client.configPath = func() (string, error) { return filepath.Join(t.TempDir(), "config.yaml"), nil }
+		client.getenv = func(string) string { return "" }
+		client.loadCatalog = loadCatalog
+		code := client.Run(append(args, "--base-url", baseURL, "--json"))
+		return code, out.String()
+	}
+
+	if code, output := run("ping", "--api-key", "mock-key"); code != 0 {
+		t.Fatalf("ping exit code = %d: %s", code, output)
+	}
+	if code, output := run("ping", "--api-key", "other"); code != 1 || !strings.Contains(output, "UNAUTHORIZED") {
+		t.Fatalf("ping with wrong key = %d: %s", code, output)
+	}
+	code, output := run("actions", "run", "invoice.create-contact", "--api-key", "mock-key", "--body", `{"name":"Acme"}`)
+	if code != 0 {
+		t.Fatalf("create exit code = %d: %s", code, output)
+	}
+	var created struct {
+		Data struct {
+			Response struct {
+				ID string `json:"id"`
+			} `json:"response"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(output), &created); err != nil || created.Data.Response.ID == "" {
+		t.Fatalf("create output = %s (%v)", output, err)
+	}
+	if code, output := run("actions", "run", "invoice.get-contact", "--api-key", "mock-key", "--path", "contactId="+created.Data.Response.ID); code != 0 || !strings.Contains(output, `"Acme"`) {
+		t.Fatalf("get = %d: %s", code, output)
+	}
+	if code, output := run("actions", "run", "invoice.create-contact", "--api-key", "mock-key", "--body", `{}`, "--skip-validation"); code != 1 || !strings.Contains(output, "VALIDATION_FAILED") {
+		t.Fatalf("invalid create = %d: %s", code, output)
+	}
+
+	stop()
+	if code := <-done; code != 0 {
+		t.Fatalf("mock serve exit code = %d: %s", code, logs.String())
+	}
+	if !strings.Contains(out.String(), "listening on "+baseURL) || !strings.Contains(logs.String(), "POST /api/invoicing/v1/contacts -> 400") {
+		t.Fatalf("stdout = %q\nlog = %q", out.String(), logs.String())
+	}
+}
+
+func TestMockServeIgnoresConfiguredCredentials(t *testing.T) {
+	t.Parallel()
+
+	serve := func(env map[string]string) (string, string) {
+		t.Helper()
+		out := &bytes.Buffer{}
+		app := NewApp(out, io.Discard)
+		app.configPath = func() (string, error) {
+			t.Error("mock serve must not read the config")
+			return filepath.Join(t.TempDir(), "config.yaml"), nil
+		}
+		app.getenv = func(key string) string { return env[key] }
+		app.loadCatalog = func(ctx context.Context, httpClient *http.Client) (actions.Catalog, error) {
+			return actions.Catalog{Actions: []actions.Action{
+				{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+			}}, nil
+		}
+		addrs := make(chan string, 1)
+		app.listen = func(network, address string) (net.Listener, error) {
+			listener, err := net.Listen(network, address)
+			if err == nil {
+				addrs <- listener.Addr().String()
+			}
+			return listener, err
+		}
+		ctx, stop := context.WithCancel(context.Background())
+		app.notifyContext = func() (context.Context, context.CancelFunc) { return ctx, stop }
+
+		done := make(chan int, 1)
+		go func() { done <- app.Run([]string{"mock", "serve", "--port", "0", "--json"}) }()
+		var addr string
+		select {
+		case addr = <-addrs:
+		case code := <-done:
+			t.Fatalf("mock serve exited early with %d: %s", code, out.String())
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/api/invoicing/v1/contacts", nil)
+		req.Header.Set("key", "client-key")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		stop()
+		if code := <-done; code != 0 {
+			t.Fatalf("mock serve exit code = %d: %s", code, out.String())
+		}
+		return resp.Status, out.String()
+	}
+
+	status, out := serve(map[string]string{"HOLDED_API_KEY": "real-key", "HOLDED_PASSPHRASE": "secret"})
+	if status != "200 OK" || !strings.Contains(out, `"any_key": true`) {
+		t.Fatalf("without a mock key: status = %s, output = %s", status, out)
+	}
+	status, out = serve(map[string]string{"HOLDED_API_KEY": "real-key", "HOLDED_MOCK_API_KEY": "mock-key"})
+	if status != "401 Unauthorized" || !strings.Contains(out, `"any_key": false`) {
+		t.Fatalf("with HOLDED_MOCK_API_KEY: status = %s, output = %s", status, out)
+	}
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jaumecornado/holdedcli/internal/holded"
+)
+
+// Formats accepted by actions run --emit.
+const (
+	emitCurl     = "curl"
+	emitHTTPie   = "httpie"
+	emitHTTPFile = "http-file"
+)
+
+// apiKeyEnvVar is referenced by emitted requests instead of the real key.
+const apiKeyEnvVar = "HOLDED_API_KEY"
+
+type emittedHeader struct {
+	name  string
+	value string
+	key   bool
+}
+
+// renderRequest renders req as a curl or HTTPie command, or as a .http file for the VS Code
+// REST Client. When filePath is set the multipart upload references the file instead of
+// inlining the encoded body.
+func renderRequest(format string, req *http.Request, body []byte, filePath string) (string, error) {
+	headers := emittedHeaders(req.Header, filePath != "" && format != emitHTTPFile)
+
+	switch format {
+	case emitCurl:
+		return renderCurl(req, headers, body, filePath), nil
+	case emitHTTPie:
+		return renderHTTPie(req, headers, body, filePath), nil
+	case emitHTTPFile:
+		return renderHTTPFile(req, headers, body, filePath)
+	default:
+		return "", fmt.Errorf("unsupported --emit %q; supported: %s, %s, %s", format, emitCurl, emitHTTPie, emitHTTPFile)
+	}
+}
+
+// emittedHeaders sorts headers, marking the API key. Tools that build multipart bodies
+// themselves set their own Content-Type boundary, so skipContentType drops ours.
+func emittedHeaders(header http.Header, skipContentType bool) []emittedHeader {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		if skipContentType && strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headers := make([]emittedHeader, 0, len(names))
+	for _, name := range names {
+		if strings.EqualFold(name, holded.APIKeyHeader) {
+			headers = append(headers, emittedHeader{name: holded.APIKeyHeader, key: true})
+			continue
+		}
+		headers = append(headers, emittedHeader{name: name, value: strings.Join(header[name], ", ")})
+	}
+	return headers
+}
+
+func renderCurl(req *http.Request, headers []emittedHeader, body []byte, filePath string) string {
+	parts := []string{"curl -X " + req.Method + " " + shellQuote(req.URL.String())}
+	for _, header := range headers {
+		if header.key {
+			parts = append(parts, fmt.Sprintf(`-H "%s: $%s"`, header.name, apiKeyEnvVar))
+			continue
+		}
+		parts = append(parts, "-H "+shellQuote(header.name+": "+header.value))
+	}
+
+	switch {
+	case filePath != "":
+		parts = append(parts, "-F "+shellQuote("file=@"+filePath))
+	case len(body) > 0:
+		parts = append(parts, "--data-binary "+shellQuote(string(body)))
+	}
+
+	return strings.Join(parts, " \\\n  ")
+}
+
+func renderHTTPie(req *http.Request, headers []emittedHeader, body []byte, filePath string) string {
+	parts := []string{"http --ignore-stdin"}
+	if filePath != "" {
+		parts = append(parts, "--multipart")
+	} else if len(body) > 0 {
+		parts = append(parts, "--raw "+shellQuote(string(body)))
+	}
+	parts = append(parts, req.Method+" "+shellQuote(req.URL.String()))
+
+	for _, header := range headers {
+		if header.key {
+			parts = append(parts, fmt.Sprintf(`%s:"$%s"`, header.name, apiKeyEnvVar))
+			continue
+		}
+		parts = append(parts, shellQuote(header.name+":"+header.value))
+	}
+	if filePath != "" {
+		parts = append(parts, "file@"+shellQuote(filePath))
+	}
+
+	return strings.Join(parts, " \\\n  ")
+}
+
+func renderHTTPFile(req *http.Request, headers []emittedHeader, body []byte, filePath string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", req.Method, req.URL.String())
+	for _, header := range headers {
+		if header.key {
+			fmt.Fprintf(&b, "%s: {{$processEnv %s}}\n", header.name, apiKeyEnvVar)
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", header.name, header.value)
+	}
+
+	switch {
+	case filePath != "":
+		_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil || params["boundary"] == "" {
+			return "", fmt.Errorf("multipart request without boundary")
+		}
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			return "", err
+		}
+
+		boundary := params["boundary"]
+		fmt.Fprintf(&b, "\n--%s\n", boundary)
+		fmt.Fprintf(&b, "Content-Disposition: form-data; name=\"file\"; filename=%q\n", filepath.Base(filePath))
+		fmt.Fprintf(&b, "Content-Type: application/octet-stream\n\n")
+		fmt.Fprintf(&b, "< %s\n", absPath)
+		fmt.Fprintf(&b, "--%s--\n", boundary)
+	case len(body) > 0:
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
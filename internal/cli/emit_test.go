@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jaumecornado/holdedcli/internal/holded"
+)
+
+func TestRenderRequest(t *testing.T) {
+	t.Parallel()
+
+	client, err := holded.NewClient("https://api.holded.com", "secret-api-key", nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	body := []byte(`{"name":"O'Brien"}`)
+	req, err := client.BuildRequest(context.Background(), holded.Request{
+		Method: http.MethodPost,
+		Path:   "/api/invoicing/v1/contacts",
+		Query:  map[string][]string{"lang": {"es"}},
+		Body:   body,
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: emitCurl, want: []string{
+			`curl -X POST 'https://api.holded.com/api/invoicing/v1/contacts?lang=es'`,
+			`-H "key: $HOLDED_API_KEY"`,
+			`-H 'Content-Type: application/json'`,
+			`--data-binary '{"name":"O'\''Brien"}'`,
+		}},
+		{format: emitHTTPie, want: []string{
+			`http --ignore-stdin`,
+			`--raw '{"name":"O'\''Brien"}'`,
+			`POST 'https://api.holded.com/api/invoicing/v1/contacts?lang=es'`,
+			`key:"$HOLDED_API_KEY"`,
+		}},
+		{format: emitHTTPFile, want: []string{
+			"POST https://api.holded.com/api/invoicing/v1/contacts?lang=es\n",
+			"key: {{$processEnv HOLDED_API_KEY}}\n",
+			"\n\n{\"name\":\"O'Brien\"}",
+		}},
+	}
+	for _, tt := range tests {
+		got, err := renderRequest(tt.format, req, body, "")
+		if err != nil {
+			t.Fatalf("renderRequest(%s) error = %v", tt.format, err)
+		}
+		if strings.Contains(got, "secret-api-key") {
+			t.Fatalf("renderRequest(%s) leaks the API key:\n%s", tt.format, got)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Fatalf("renderRequest(%s) missing %q in:\n%s", tt.format, want, got)
+			}
+		}
+	}
+
+	if _, err := renderRequest("wget", req, body, ""); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
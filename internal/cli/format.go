@@ -0,0 +1,359 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatTable = "table"
+	formatCSV   = "csv"
+	formatTSV   = "tsv"
+	formatYAML  = "yaml"
+	formatJSON  = "json"
+
+	maxTableCell = 60
+)
+
+// renderFormats are the --output values accepted by render.
+var renderFormats = []string{formatTable, formatCSV, formatTSV, formatYAML, formatJSON}
+
+// parseColumns splits a --columns value into dotted paths.
+func parseColumns(value string) []string {
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// record is one row of a response: a decoded value plus the key order of its object.
+type record struct {
+	keys  []string
+	value any
+}
+
+// decodeRecords splits a JSON body into rows: the items of an array, or the value itself.
+func decodeRecords(body []byte) ([]record, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	var items []json.RawMessage
+	if trimmed[0] != '[' {
+		items = []json.RawMessage{trimmed}
+	} else if err := json.Unmarshal(trimmed, &items); err != nil {
+		return nil, err
+	}
+
+	records := make([]record, 0, len(items))
+	for _, item := range items {
+		value, err := decodeJSONNumber(item)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record{keys: objectKeys(item), value: value})
+	}
+	return records, nil
+}
+
+func decodeJSONNumber(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// objectKeys returns the top-level keys of a JSON object in document order.
+func objectKeys(data []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+		key, _ := tok.(string)
+		keys = append(keys, key)
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return keys
+		}
+	}
+	return keys
+}
+
+// inferColumns is the union of the records' keys in order of first appearance.
+func inferColumns(records []record) []string {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, rec := range records {
+		for _, key := range rec.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	return columns
+}
+
+// lookupPath resolves a dotted path such as customer.name or items.0.price. Numeric
+// segments index arrays.
+func lookupPath(value any, path string) (any, bool) {
+	if path == "" {
+		return value, true
+	}
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// cellText renders a value for table, CSV and TSV cells; nested values become compact JSON.
+func cellText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
+
+// render writes body as format. Scalar rows in tabular formats get a single "value" column.
+func render(w io.Writer, format string, body []byte, columns []string) error {
+	records, err := decodeRecords(body)
+	if err != nil {
+		return fmt.Errorf("response is not JSON: %w", err)
+	}
+
+	if len(columns) == 0 && (format == formatYAML || format == formatJSON) {
+		value, err := decodeJSONNumber(body)
+		if err != nil && len(bytes.TrimSpace(body)) > 0 {
+			return fmt.Errorf("response is not JSON: %w", err)
+		}
+		return writeStructured(w, format, value)
+	}
+
+	if len(columns) == 0 {
+		columns = inferColumns(records)
+	}
+	if len(columns) == 0 {
+		columns = []string{"value"}
+		for i := range records {
+			records[i].value = map[string]any{"value": records[i].value}
+		}
+	}
+
+	rows := make([][]any, len(records))
+	for i, rec := range records {
+		rows[i] = make([]any, len(columns))
+		for j, column := range columns {
+			rows[i][j], _ = lookupPath(rec.value, column)
+		}
+	}
+
+	switch format {
+	case formatTable:
+		return writeTable(w, columns, rows)
+	case formatCSV:
+		return writeCSV(w, columns, rows)
+	case formatTSV:
+		return writeTSV(w, columns, rows)
+	default:
+		selected := make([]orderedRow, len(rows))
+		for i, row := range rows {
+			selected[i] = orderedRow{keys: columns, values: row}
+		}
+		return writeStructured(w, format, selected)
+	}
+}
+
+func writeStructured(w io.Writer, format string, value any) error {
+	if format == formatYAML {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(yamlValue(value)); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+func writeTable(w io.Writer, columns []string, rows [][]any) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = truncateCell(singleLine(cellText(value)))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, columns []string, rows [][]any) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = cellText(value)
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTSV writes tab-separated values; tabs and newlines inside cells become spaces.
+func writeTSV(w io.Writer, columns []string, rows [][]any) error {
+	if _, err := fmt.Fprintln(w, strings.Join(columns, "\t")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = singleLine(strings.ReplaceAll(cellText(value), "\t", " "))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+func truncateCell(s string) string {
+	if utf8.RuneCountInString(s) <= maxTableCell {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:maxTableCell-1]) + "…"
+}
+
+// orderedRow marshals the selected columns in --columns order.
+type orderedRow struct {
+	keys   []string
+	values []any
+}
+
+func (r orderedRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (r orderedRow) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i, key := range r.keys {
+		var value yaml.Node
+		if err := value.Encode(yamlValue(r.values[i])); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &value)
+	}
+	return node, nil
+}
+
+// yamlValue converts json.Number so YAML prints numbers instead of quoted strings.
+func yamlValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for key, item := range v {
+			converted[key] = yamlValue(item)
+		}
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, item := range v {
+			converted[i] = yamlValue(item)
+		}
+		return converted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`[
+		{"id":"d1","contact":{"name":"Acme, S.L."},"total":121.5,"items":[{"sku":"A"}]},
+		{"id":"d2","contact":{"name":"Globex"},"total":10,"paid":true}
+	]`)
+
+	tests := []struct {
+		format  string
+		columns []string
+		want    string
+	}{
+		{
+			format:  formatTable,
+			columns: []string{"id", "contact.name", "total", "items.0.sku"},
+			want: "id  contact.name  total  items.0.sku\n" +
+				"d1  Acme, S.L.    121.5  A\n" +
+				"d2  Globex        10     \n",
+		},
+		{
+			format:  formatCSV,
+			columns: []string{"id", "contact.name", "total"},
+			want:    "id,contact.name,total\nd1,\"Acme, S.L.\",121.5\nd2,Globex,10\n",
+		},
+		{
+			format:  formatTSV,
+			columns: []string{"id", "paid"},
+			want:    "id\tpaid\nd1\t\nd2\ttrue\n",
+		},
+		{
+			format:  formatYAML,
+			columns: []string{"id", "total"},
+			want:    "- id: d1\n  total: 121.5\n- id: d2\n  total: 10\n",
+		},
+		{
+			format:  formatJSON,
+			columns: []string{"total", "id"},
+			want:    "[\n  {\n    \"total\": 121.5,\n    \"id\": \"d1\"\n  },\n  {\n    \"total\": 10,\n    \"id\": \"d2\"\n  }\n]\n",
+		},
+		{
+			// Without --columns, tabular formats use the top-level keys in document order.
+			format: formatCSV,
+			want: "id,contact,total,items,paid\n" +
+				"d1,\"{\"\"name\"\":\"\"Acme, S.L.\"\"}\",121.5,\"[{\"\"sku\"\":\"\"A\"\"}]\",\n" +
+				"d2,\"{\"\"name\"\":\"\"Globex\"\"}\",10,,true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := render(&out, tt.format, body, tt.columns); err != nil {
+			t.Fatalf("render(%s) error = %v", tt.format, err)
+		}
+		if out.String() != tt.want {
+			t.Fatalf("render(%s) =\n%s\nwant\n%s", tt.format, out.String(), tt.want)
+		}
+	}
+}
+
+func TestRenderSingleObjectAndScalars(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := render(&out, formatTable, []byte(`{"id":"c1","name":"Acme"}`), nil); err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	if want := "id  name\nc1  Acme\n"; out.String() != want {
+		t.Fatalf("render() = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := render(&out, formatCSV, []byte(`["a","b"]`), nil); err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	if want := "value\na\nb\n"; out.String() != want {
+		t.Fatalf("render() = %q, want %q", out.String(), want)
+	}
+
+	if err := render(&out, formatTable, []byte(`not json`), nil); err == nil {
+		t.Fatal("render() error = nil for a non-JSON body")
+	}
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jaumecornado/holdedcli/internal/humanize"
+)
+
+const defaultDateLayout = "2006-01-02"
+
+// templateFuncs are the helpers available to --template and --template-file. The date
+// helper renders in local time until setTemplateLocation picks the configured timezone.
+var templateFuncs = template.FuncMap{
+	"money":   formatMoney,
+	"date":    dateFormatter(time.Local),
+	"json":    toJSON,
+	"default": defaultValue,
+}
+
+// setTemplateLocation makes the date helper of tmpl render timestamps in loc, the
+// timezone --humanize uses, so both show the same Holded timestamp the same way.
+func setTemplateLocation(tmpl *template.Template, loc *time.Location) {
+	tmpl.Funcs(template.FuncMap{"date": dateFormatter(loc)})
+}
+
+// parseTemplate compiles --template or --template-file; both empty returns nil.
+func parseTemplate(text, file string) (*template.Template, error) {
+	text, file = strings.TrimSpace(text), strings.TrimSpace(file)
+	switch {
+	case text != "" && file != "":
+		return nil, &usageError{message: "use either --template or --template-file, not both"}
+	case file != "":
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, &usageError{message: fmt.Sprintf("reading --template-file: %v", err)}
+		}
+		text = string(content)
+	case text == "":
+		return nil, nil
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, &usageError{message: fmt.Sprintf("invalid template: %v", err)}
+	}
+	return tmpl, nil
+}
+
+// toTemplateData converts v to the generic JSON form, so templates use the same keys as --json.
+func toTemplateData(v any) (any, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var data any
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// formatMoney renders an amount with two decimals and thousands separators, followed by
+// an optional currency code: {{money .total "EUR"}} -> 1,234.50 EUR.
+func formatMoney(value any, currency ...string) (string, error) {
+	amount, err := toFloat(value)
+	if err != nil {
+		return "", fmt.Errorf("money: %w", err)
+	}
+
+	code := ""
+	if len(currency) > 0 {
+		code = currency[0]
+	}
+	return humanize.Money(amount, code, humanize.DefaultLocale), nil
+}
+
+func dateFormatter(loc *time.Location) func(value any, layout ...string) (string, error) {
+	return func(value any, layout ...string) (string, error) {
+		return formatDate(loc, value, layout...)
+	}
+}
+
+// formatDate renders a Holded Unix timestamp (seconds) in loc, as 2006-01-02 unless a Go
+// layout is given: {{date .date "02/01/2006 15:04"}}. Empty values render as "".
+func formatDate(loc *time.Location, value any, layout ...string) (string, error) {
+	if value == nil || value == "" {
+		return "", nil
+	}
+	seconds, err := toFloat(value)
+	if err != nil {
+		return "", fmt.Errorf("date: %w", err)
+	}
+
+	format := defaultDateLayout
+	if len(layout) > 0 && layout[0] != "" {
+		format = layout[0]
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9)).In(loc).Format(format), nil
+}
+
+func toJSON(value any) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// defaultValue returns fallback when value is nil, an empty string or an empty array or
+// object; zero numbers and false are kept. It takes the value last so it works in
+// pipelines: {{.notes | default "-"}}.
+func defaultValue(fallback, value any) any {
+	if value == nil {
+		return fallback
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rv.Len() == 0 {
+			return fallback
+		}
+	}
+	return value
+}
+
+func toFloat(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("%v (%T) is not a number", value, value)
+	}
+}
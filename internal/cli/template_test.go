@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTemplateHelpers(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := parseTemplate(`{{range .}}{{.docNumber}} {{money .total "EUR"}} {{date .date}} {{date .date "02/01/2006"}} {{.notes | default "-"}} {{json .tags}}
+{{end}}`, "")
+	if err != nil {
+		t.Fatalf("parseTemplate() error = %v", err)
+	}
+
+	noon := time.Date(2025, 3, 7, 12, 0, 0, 0, time.Local).Unix()
+	data := []any{
+		map[string]any{"docNumber": "F001", "total": 1234567.891, "date": float64(noon), "notes": "", "tags": []any{"a"}},
+		map[string]any{"docNumber": "F002", "total": -5.5, "date": float64(noon), "notes": "paid", "tags": nil},
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := "F001 1,234,567.89 EUR 2025-03-07 07/03/2025 - [\"a\"]\n" +
+		"F002 -5.50 EUR 2025-03-07 07/03/2025 paid null\n"
+	if out.String() != want {
+		t.Fatalf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestTemplateDateLocation(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := parseTemplate(`{{date .date "2006-01-02 15:04 MST"}}`, "")
+	if err != nil {
+		t.Fatalf("parseTemplate() error = %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+	setTemplateLocation(tmpl, tokyo)
+
+	// 2026-09-30 22:00 UTC is already October 1 in Tokyo, whatever the machine's TZ.
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, map[string]any{"date": float64(1790805600)}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if out.String() != "2026-10-01 07:00 JST" {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseTemplate("{{.x", ""); err == nil {
+		t.Fatal("parseTemplate() error = nil for an unterminated action")
+	}
+	if _, err := parseTemplate("{{.x}}", "tmpl.txt"); err == nil {
+		t.Fatal("parseTemplate() error = nil with both --template and --template-file")
+	}
+	if tmpl, err := parseTemplate("", ""); tmpl != nil || err != nil {
+		t.Fatalf("parseTemplate() = %v, %v; want nil, nil", tmpl, err)
+	}
+	if _, err := formatMoney("abc"); err == nil {
+		t.Fatal("formatMoney() error = nil for a non-number")
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// disableEcho hides typed input on Unix terminals through stty and returns a function
+// restoring it. It is a no-op where stty is unavailable.
+func disableEcho() func() {
+	if runtime.GOOS == "windows" || !stdinIsTerminal() {
+		return func() {}
+	}
+
+	off := exec.Command("stty", "-echo")
+	off.Stdin = os.Stdin
+	if err := off.Run(); err != nil {
+		return func() {}
+	}
+
+	return func() {
+		on := exec.Command("stty", "echo")
+		on.Stdin = os.Stdin
+		_ = on.Run()
+	}
+}
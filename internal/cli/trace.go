@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaumecornado/holdedcli/internal/holded"
+)
+
+type traceData struct {
+	Attempt         int             `json:"attempt"`
+	Method          string          `json:"method"`
+	URL             string          `json:"url"`
+	RequestHeaders  http.Header     `json:"request_headers"`
+	BodySize        int             `json:"body_size"`
+	StatusCode      int             `json:"status_code,omitempty"`
+	ResponseHeaders http.Header     `json:"response_headers,omitempty"`
+	Timing          traceTimingData `json:"timing"`
+	ErrorBody       string          `json:"error_body,omitempty"`
+	Error           string          `json:"error,omitempty"`
+}
+
+type traceTimingData struct {
+	DNSMS       float64 `json:"dns_ms"`
+	ConnectMS   float64 `json:"connect_ms"`
+	TLSMS       float64 `json:"tls_ms"`
+	FirstByteMS float64 `json:"first_byte_ms"`
+	TotalMS     float64 `json:"total_ms"`
+	Reused      bool    `json:"reused_connection"`
+}
+
+// traceClient makes client report every attempt when --verbose/--trace is set: it is
+// logged to stderr immediately and kept for the trace field of the JSON envelope.
+func (a *App) traceClient(client *holded.Client) {
+	if !a.trace {
+		return
+	}
+	client.SetTracer(func(trace holded.Trace) {
+		data := newTraceData(trace)
+		a.traces = append(a.traces, data)
+		writeTrace(a.errOut, data)
+	})
+}
+
+func newTraceData(trace holded.Trace) traceData {
+	return traceData{
+		Attempt:         trace.Attempt,
+		Method:          trace.Method,
+		URL:             trace.URL,
+		RequestHeaders:  trace.RequestHeaders,
+		BodySize:        trace.BodySize,
+		StatusCode:      trace.StatusCode,
+		ResponseHeaders: trace.ResponseHeaders,
+		Timing: traceTimingData{
+			DNSMS:       milliseconds(trace.Timing.DNS),
+			ConnectMS:   milliseconds(trace.Timing.Connect),
+			TLSMS:       milliseconds(trace.Timing.TLS),
+			FirstByteMS: milliseconds(trace.Timing.FirstByte),
+			TotalMS:     milliseconds(trace.Timing.Total),
+			Reused:      trace.Timing.Reused,
+		},
+		ErrorBody: trace.ErrorBody,
+		Error:     trace.Err,
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// writeTrace prints an attempt in the style of curl -v: "> " for the request, "< " for
+// the response and "* " for timing and errors.
+func writeTrace(w io.Writer, trace traceData) {
+	fmt.Fprintf(w, "* attempt %d\n", trace.Attempt)
+	fmt.Fprintf(w, "> %s %s\n", trace.Method, trace.URL)
+	writeTraceHeaders(w, "> ", trace.RequestHeaders)
+	fmt.Fprintf(w, "> body: %d bytes\n", trace.BodySize)
+
+	if trace.StatusCode != 0 {
+		fmt.Fprintf(w, "< %d %s\n", trace.StatusCode, http.StatusText(trace.StatusCode))
+		writeTraceHeaders(w, "< ", trace.ResponseHeaders)
+	}
+
+	timing := trace.Timing
+	fmt.Fprintf(w, "* timing: dns=%.1fms connect=%.1fms tls=%.1fms first_byte=%.1fms total=%.1fms reused=%t\n",
+		timing.DNSMS, timing.ConnectMS, timing.TLSMS, timing.FirstByteMS, timing.TotalMS, timing.Reused)
+
+	if trace.ErrorBody != "" {
+		fmt.Fprintln(w, "< error body:")
+		fmt.Fprintln(w, strings.TrimRight(trace.ErrorBody, "\n"))
+	}
+	if trace.Error != "" {
+		fmt.Fprintf(w, "* error: %s\n", trace.Error)
+	}
+}
+
+func writeTraceHeaders(w io.Writer, prefix string, headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range headers[name] {
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -11,8 +13,44 @@ import (
 
 const apiKeyEnvName = "HOLDED_CONFIG_PATH"
 
+// DefaultProfile names the top-level api_key and settings of config.yaml.
+const DefaultProfile = "default"
+
+var ErrProfileNotFound = errors.New("profile not found")
+
 type Config struct {
-	APIKey string `yaml:"api_key"`
+	APIKey          string             `yaml:"api_key"`
+	APIKeyCommand   string             `yaml:"api_key_command,omitempty"`
+	CredentialStore string             `yaml:"credential_store,omitempty"`
+	CurrentProfile  string             `yaml:"current_profile,omitempty"`
+	Profiles        map[string]Profile `yaml:"profiles,omitempty"`
+	RateLimit       RateLimitConfig    `yaml:"rate_limit,omitempty"`
+	ReadOnly        bool               `yaml:"read_only,omitempty"`
+	AllowActions    []string           `yaml:"allow_actions,omitempty"`
+	DenyActions     []string           `yaml:"deny_actions,omitempty"`
+	AuditLog        string             `yaml:"audit_log,omitempty"`
+	Timezone        string             `yaml:"timezone,omitempty"`
+	Locale          string             `yaml:"locale,omitempty"`
+}
+
+type Profile struct {
+	APIKey        string          `yaml:"api_key,omitempty"`
+	APIKeyCommand string          `yaml:"api_key_command,omitempty"`
+	BaseURL       string          `yaml:"base_url,omitempty"`
+	RateLimit     RateLimitConfig `yaml:"rate_limit,omitempty"`
+	ReadOnly      bool            `yaml:"read_only,omitempty"`
+	AllowActions  []string        `yaml:"allow_actions,omitempty"`
+	DenyActions   []string        `yaml:"deny_actions,omitempty"`
+
+	// BaseAllowActions holds the top-level allow_actions when the profile sets its own
+	// list; an action must then match both, so a profile can only narrow the top level.
+	BaseAllowActions []string `yaml:"-"`
+}
+
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second,omitempty"`
+	Burst             int     `yaml:"burst,omitempty"`
+	Shared            bool    `yaml:"shared,omitempty"`
 }
 
 func DefaultPath() (string, error) {
@@ -43,12 +81,12 @@ func Load(path string) (Config, error) {
 		return Config{}, err
 	}
 
-	cfg.APIKey = strings.TrimSpace(cfg.APIKey)
+	cfg.trim()
 	return cfg, nil
 }
 
 func Save(path string, cfg Config) error {
-	cfg.APIKey = strings.TrimSpace(cfg.APIKey)
+	cfg.trim()
 
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
@@ -61,3 +99,108 @@ func Save(path string, cfg Config) error {
 
 	return os.WriteFile(path, b, 0o600)
 }
+
+func (c *Config) trim() {
+	c.APIKey = strings.TrimSpace(c.APIKey)
+	c.APIKeyCommand = strings.TrimSpace(c.APIKeyCommand)
+	c.CredentialStore = strings.TrimSpace(c.CredentialStore)
+	c.CurrentProfile = strings.TrimSpace(c.CurrentProfile)
+	c.AuditLog = strings.TrimSpace(c.AuditLog)
+	c.Timezone = strings.TrimSpace(c.Timezone)
+	c.Locale = strings.TrimSpace(c.Locale)
+	for name, profile := range c.Profiles {
+		profile.APIKey = strings.TrimSpace(profile.APIKey)
+		profile.APIKeyCommand = strings.TrimSpace(profile.APIKeyCommand)
+		profile.BaseURL = strings.TrimSpace(profile.BaseURL)
+		c.Profiles[name] = profile
+	}
+}
+
+// Profile returns the named profile; an empty name or DefaultProfile selects the top-level
+// settings unless a profile with that name exists. Profiles without their own rate_limit or
+// allow_actions inherit the top-level ones, while the top-level read_only, allow_actions
+// and deny_actions always apply so a profile can only narrow them.
+func (c Config) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if name != DefaultProfile {
+			return Profile{}, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+		}
+		profile = Profile{
+			APIKey:        c.APIKey,
+			APIKeyCommand: c.APIKeyCommand,
+			ReadOnly:      c.ReadOnly,
+			DenyActions:   c.DenyActions,
+		}
+	}
+
+	if profile.RateLimit == (RateLimitConfig{}) {
+		profile.RateLimit = c.RateLimit
+	}
+	if len(profile.AllowActions) == 0 {
+		profile.AllowActions = c.AllowActions
+	} else if ok && len(c.AllowActions) > 0 {
+		profile.BaseAllowActions = c.AllowActions
+	}
+	if ok {
+		profile.ReadOnly = profile.ReadOnly || c.ReadOnly
+		profile.DenyActions = append(append([]string(nil), c.DenyActions...), profile.DenyActions...)
+	}
+	return profile, nil
+}
+
+// SetAPIKey stores key in the named profile, creating it if needed.
+func (c *Config) SetAPIKey(name, key string) {
+	if _, ok := c.Profiles[name]; !ok && (name == "" || name == DefaultProfile) {
+		c.APIKey = key
+		return
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]Profile)
+	}
+	profile := c.Profiles[name]
+	profile.APIKey = key
+	c.Profiles[name] = profile
+}
+
+// ProfileNames lists DefaultProfile followed by the named profiles in alphabetical order.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles)+1)
+	for name := range c.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// CatalogCachePath returns actions.json next to config.yaml, where actions refresh and
+// actions import write the catalog.
+func CatalogCachePath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "actions.json")
+}
+
+// RateLimitStatePath returns ratelimit.json next to config.yaml, the token bucket
+// shared by processes using rate_limit.shared.
+func RateLimitStatePath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "ratelimit.json")
+}
+
+// AuditLogPath returns audit_log (relative paths are resolved against the config
+// directory) or audit.jsonl next to config.yaml.
+func (c Config) AuditLogPath(configPath string) string {
+	switch {
+	case c.AuditLog == "":
+		return filepath.Join(filepath.Dir(configPath), "audit.jsonl")
+	case filepath.IsAbs(c.AuditLog):
+		return c.AuditLog
+	default:
+		return filepath.Join(filepath.Dir(configPath), c.AuditLog)
+	}
+}
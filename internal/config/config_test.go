@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"path/filepath"
 	"testing"
 )
@@ -24,3 +25,112 @@ func TestSaveAndLoad(t *testing.T) {
 		t.Fatalf("APIKey = %q, want %q", got.APIKey, want.APIKey)
 	}
 }
+
+func TestSaveAndLoadRateLimit(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	want := Config{APIKey: "abc123", RateLimit: RateLimitConfig{RequestsPerSecond: 2.5, Burst: 5, Shared: true}}
+
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got.RateLimit != want.RateLimit {
+		t.Fatalf("RateLimit = %+v, want %+v", got.RateLimit, want.RateLimit)
+	}
+}
+
+func TestConfigProfiles(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := Config{APIKey: "default-key", RateLimit: RateLimitConfig{RequestsPerSecond: 2}}
+	cfg.SetAPIKey("acme", " acme-key ")
+	cfg.Profiles["acme"] = Profile{APIKey: cfg.Profiles["acme"].APIKey, BaseURL: "https://acme.example"}
+
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	acme, err := got.Profile("acme")
+	if err != nil {
+		t.Fatalf("Profile(acme) error = %v", err)
+	}
+	if acme.APIKey != "acme-key" || acme.BaseURL != "https://acme.example" {
+		t.Fatalf("acme profile = %+v", acme)
+	}
+	if acme.RateLimit.RequestsPerSecond != 2 {
+		t.Fatalf("acme should inherit the top-level rate limit, got %+v", acme.RateLimit)
+	}
+
+	def, err := got.Profile("")
+	if err != nil || def.APIKey != "default-key" {
+		t.Fatalf("default profile = %+v, err = %v", def, err)
+	}
+
+	if _, err := got.Profile("missing"); !errors.Is(err, ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound, got %v", err)
+	}
+
+	names := got.ProfileNames()
+	if len(names) != 2 || names[0] != DefaultProfile || names[1] != "acme" {
+		t.Fatalf("ProfileNames() = %v", names)
+	}
+}
+
+func TestProfileAccessPolicyInheritance(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		ReadOnly:     true,
+		AllowActions: []string{"invoice.*"},
+		DenyActions:  []string{"*.delete-*"},
+		Profiles: map[string]Profile{
+			"analyst": {DenyActions: []string{"team.*"}},
+			"agent":   {AllowActions: []string{"invoice.list-*"}},
+		},
+	}
+
+	analyst, err := cfg.Profile("analyst")
+	if err != nil {
+		t.Fatalf("Profile(analyst) error = %v", err)
+	}
+	if !analyst.ReadOnly {
+		t.Fatalf("top-level read_only must apply to every profile")
+	}
+	if len(analyst.DenyActions) != 2 || analyst.DenyActions[0] != "*.delete-*" || analyst.DenyActions[1] != "team.*" {
+		t.Fatalf("DenyActions = %v", analyst.DenyActions)
+	}
+	if len(analyst.AllowActions) != 1 || analyst.AllowActions[0] != "invoice.*" {
+		t.Fatalf("AllowActions = %v, want inherited top-level list", analyst.AllowActions)
+	}
+
+	agent, err := cfg.Profile("agent")
+	if err != nil {
+		t.Fatalf("Profile(agent) error = %v", err)
+	}
+	if len(agent.AllowActions) != 1 || agent.AllowActions[0] != "invoice.list-*" {
+		t.Fatalf("AllowActions = %v, want the profile's own list", agent.AllowActions)
+	}
+	if len(agent.BaseAllowActions) != 1 || agent.BaseAllowActions[0] != "invoice.*" {
+		t.Fatalf("BaseAllowActions = %v, want the top-level list", agent.BaseAllowActions)
+	}
+	if len(analyst.BaseAllowActions) != 0 {
+		t.Fatalf("analyst BaseAllowActions = %v, want none for an inherited list", analyst.BaseAllowActions)
+	}
+
+	def, _ := cfg.Profile(DefaultProfile)
+	if !def.ReadOnly || len(def.DenyActions) != 1 {
+		t.Fatalf("default profile = %+v", def)
+	}
+}
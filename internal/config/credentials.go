@@ -0,0 +1,256 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Credential store names accepted by credential_store in config.yaml.
+const (
+	StoreConfig    = "config"
+	StoreEncrypted = "encrypted"
+)
+
+const (
+	encryptedFileVersion = 1
+	pbkdf2Iterations     = 600000
+	commandTimeout       = 30 * time.Second
+)
+
+var (
+	ErrCredentialNotFound = errors.New("credential not found")
+	ErrReadOnlyStore      = errors.New("credential store is read-only")
+	ErrWrongPassphrase    = errors.New("wrong passphrase or corrupt credentials file")
+)
+
+// CredentialStore supplies API keys per profile from outside the plaintext config.yaml.
+// Get returns ErrCredentialNotFound when the store has no key for the profile.
+type CredentialStore interface {
+	Get(profile string) (string, error)
+	Set(profile, key string) error
+}
+
+func CredentialsPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "credentials.enc")
+}
+
+// EncryptedFileStore keeps API keys in a file encrypted with AES-256-GCM under a key
+// derived from a passphrase with PBKDF2-SHA256. The passphrase is requested lazily.
+type EncryptedFileStore struct {
+	path       string
+	passphrase func() (string, error)
+	iterations int
+}
+
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func NewEncryptedFileStore(path string, passphrase func() (string, error)) *EncryptedFileStore {
+	return &EncryptedFileStore{path: path, passphrase: passphrase, iterations: pbkdf2Iterations}
+}
+
+func (s *EncryptedFileStore) Get(profile string) (string, error) {
+	if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+		return "", ErrCredentialNotFound
+	}
+
+	passphrase, err := s.readPassphrase()
+	if err != nil {
+		return "", err
+	}
+
+	keys, err := s.load(passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	key, ok := keys[profile]
+	if !ok || key == "" {
+		return "", ErrCredentialNotFound
+	}
+	return key, nil
+}
+
+func (s *EncryptedFileStore) Set(profile, key string) error {
+	return s.SetKeys(map[string]string{profile: key})
+}
+
+// SetKeys stores the keys of several profiles, asking for the passphrase once.
+func (s *EncryptedFileStore) SetKeys(profileKeys map[string]string) error {
+	passphrase, err := s.readPassphrase()
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.load(passphrase)
+	if err != nil {
+		return err
+	}
+	for profile, key := range profileKeys {
+		keys[profile] = strings.TrimSpace(key)
+	}
+
+	return s.save(passphrase, keys)
+}
+
+func (s *EncryptedFileStore) readPassphrase() (string, error) {
+	if s.passphrase == nil {
+		return "", errors.New("no passphrase available for encrypted credentials")
+	}
+
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase: %w", err)
+	}
+	if passphrase == "" {
+		return "", errors.New("empty passphrase for encrypted credentials")
+	}
+	return passphrase, nil
+}
+
+func (s *EncryptedFileStore) load(passphrase string) (map[string]string, error) {
+	keys := make(map[string]string)
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return keys, nil
+		}
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("decoding credentials file: %w", err)
+	}
+	if file.Version != encryptedFileVersion {
+		return nil, fmt.Errorf("unsupported credentials file version %d", file.Version)
+	}
+
+	gcm, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	if err := json.Unmarshal(plaintext, &keys); err != nil {
+		return nil, fmt.Errorf("decoding credentials: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *EncryptedFileStore) save(passphrase string, keys map[string]string) error {
+	plaintext, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{
+		Version:    encryptedFileVersion,
+		KDF:        "pbkdf2-sha256",
+		Iterations: s.iterations,
+		Salt:       make([]byte, 16),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	b, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0o600)
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// CommandStore reads the API key from the standard output of a shell command
+// such as `pass show holded`. It cannot store keys.
+type CommandStore struct {
+	command string
+}
+
+func NewCommandStore(command string) *CommandStore {
+	return &CommandStore{command: command}
+}
+
+func (s *CommandStore) Get(profile string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", s.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", s.command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("running api_key_command: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("running api_key_command: %w", err)
+	}
+
+	// Secret managers like pass store extra lines after the secret.
+	key, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", ErrCredentialNotFound
+	}
+	return key, nil
+}
+
+func (s *CommandStore) Set(profile, key string) error {
+	return ErrReadOnlyStore
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEncryptedFileStore(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	passphrase := "correct horse"
+	store := NewEncryptedFileStore(path, func() (string, error) { return passphrase, nil })
+	store.iterations = 1000
+
+	if _, err := store.Get("acme"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("Get() on missing file error = %v, want ErrCredentialNotFound", err)
+	}
+
+	if err := store.Set("acme", "acme-key"); err != nil {
+		t.Fatalf("Set(acme) error = %v", err)
+	}
+	if err := store.Set(DefaultProfile, "default-key"); err != nil {
+		t.Fatalf("Set(default) error = %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading credentials file: %v", err)
+	}
+	if strings.Contains(string(raw), "acme-key") {
+		t.Fatalf("credentials file stores the key in plaintext: %s", raw)
+	}
+
+	if key, err := store.Get("acme"); err != nil || key != "acme-key" {
+		t.Fatalf("Get(acme) = %q, %v", key, err)
+	}
+	if _, err := store.Get("globex"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("Get(globex) error = %v, want ErrCredentialNotFound", err)
+	}
+
+	passphrase = "wrong"
+	if _, err := store.Get("acme"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("Get() with wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestCommandStore(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	key, err := NewCommandStore(`printf 'cmd-key\nlogin: me\n'`).Get(DefaultProfile)
+	if err != nil || key != "cmd-key" {
+		t.Fatalf("Get() = %q, %v", key, err)
+	}
+
+	if _, err := NewCommandStore("echo boom >&2; exit 3").Get(DefaultProfile); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected command failure with stderr, got %v", err)
+	}
+
+	if err := NewCommandStore("true").Set(DefaultProfile, "x"); !errors.Is(err, ErrReadOnlyStore) {
+		t.Fatalf("Set() error = %v, want ErrReadOnlyStore", err)
+	}
+}
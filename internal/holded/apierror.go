@@ -0,0 +1,119 @@
+package holded
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldError is a validation error reported for a single request field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// newAPIError builds the APIError for a non-2xx response, parsing Holded's JSON error
+// payloads. Bodies that are not JSON objects only fill BodySnippet.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, BodySnippet: cleanSnippet(string(body))}
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return apiErr
+	}
+
+	apiErr.Status = scalarString(payload["status"])
+	apiErr.Message = firstString(payload, "message", "msg", "error")
+	apiErr.Info = scalarString(payload["info"])
+	apiErr.FieldErrors = parseFieldErrors(payload["errors"])
+	if apiErr.Message == "" && len(apiErr.FieldErrors) == 0 {
+		// {"error": {"message": ..., "errors": ...}}
+		var nested map[string]json.RawMessage
+		if json.Unmarshal(payload["error"], &nested) == nil {
+			apiErr.Message = firstString(nested, "message", "msg")
+			apiErr.FieldErrors = parseFieldErrors(nested["errors"])
+		}
+	}
+
+	return apiErr
+}
+
+func firstString(payload map[string]json.RawMessage, keys ...string) string {
+	for _, key := range keys {
+		if value := scalarString(payload[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// scalarString returns a JSON string, number or boolean as text, and "" for anything else.
+func scalarString(raw json.RawMessage) string {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// parseFieldErrors accepts the shapes Holded uses for validation errors: an object keyed
+// by field ({"name": "required"} or {"name": ["required"]}), or a list of strings or of
+// objects with field/param/path and message/msg keys.
+func parseFieldErrors(raw json.RawMessage) []FieldError {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var byField map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &byField); err == nil {
+		fields := make([]string, 0, len(byField))
+		for field := range byField {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		var errs []FieldError
+		for _, field := range fields {
+			var messages []string
+			if json.Unmarshal(byField[field], &messages) != nil {
+				messages = []string{scalarString(byField[field])}
+			}
+			for _, message := range messages {
+				errs = append(errs, FieldError{Field: field, Message: message})
+			}
+		}
+		return errs
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil
+	}
+
+	var errs []FieldError
+	for _, item := range list {
+		if message := scalarString(item); message != "" {
+			errs = append(errs, FieldError{Message: message})
+			continue
+		}
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(item, &obj) != nil {
+			continue
+		}
+		errs = append(errs, FieldError{
+			Field:   firstString(obj, "field", "param", "path", "name"),
+			Message: firstString(obj, "message", "msg", "error"),
+		})
+	}
+	return errs
+}
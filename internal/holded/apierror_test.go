@@ -0,0 +1,64 @@
+package holded
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewAPIErrorParsesPayloads(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want APIError
+	}{
+		{
+			name: "status and info",
+			body: `{"status":0,"info":"Contact not found"}`,
+			want: APIError{Status: "0", Info: "Contact not found"},
+		},
+		{
+			name: "message with field map",
+			body: `{"message":"Validation failed","errors":{"name":"is required","email":["is invalid","is too long"]}}`,
+			want: APIError{Message: "Validation failed", FieldErrors: []FieldError{
+				{Field: "email", Message: "is invalid"},
+				{Field: "email", Message: "is too long"},
+				{Field: "name", Message: "is required"},
+			}},
+		},
+		{
+			name: "field list",
+			body: `{"errors":[{"param":"date","msg":"must be a timestamp"},"items are required"]}`,
+			want: APIError{FieldErrors: []FieldError{
+				{Field: "date", Message: "must be a timestamp"},
+				{Message: "items are required"},
+			}},
+		},
+		{
+			name: "nested error object",
+			body: `{"error":{"message":"Bad request","errors":[{"field":"docType","message":"unknown"}]}}`,
+			want: APIError{Message: "Bad request", FieldErrors: []FieldError{{Field: "docType", Message: "unknown"}}},
+		},
+		{
+			name: "not json",
+			body: `<html>Bad Gateway</html>`,
+			want: APIError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := newAPIError(400, []byte(tt.body))
+			if got.StatusCode != 400 || got.BodySnippet != cleanSnippet(tt.body) {
+				t.Fatalf("status/snippet = %d %q", got.StatusCode, got.BodySnippet)
+			}
+			got.StatusCode, got.BodySnippet = 0, ""
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Fatalf("newAPIError() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
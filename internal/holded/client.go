@@ -8,48 +8,70 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 const (
 	DefaultBaseURL  = "https://api.holded.com"
 	DefaultPingPath = "/api/invoicing/v1/contacts"
+	APIKeyHeader    = "key"
 	userAgent       = "holdedcli/0.3.6"
 )
 
 type CredentialSource string
 
 const (
-	CredentialSourceFlag   CredentialSource = "flag"
-	CredentialSourceEnv    CredentialSource = "env"
-	CredentialSourceConfig CredentialSource = "config"
-	CredentialSourceNone   CredentialSource = "none"
+	CredentialSourceFlag      CredentialSource = "flag"
+	CredentialSourceEnv       CredentialSource = "env"
+	CredentialSourceConfig    CredentialSource = "config"
+	CredentialSourceEncrypted CredentialSource = "encrypted"
+	CredentialSourceCommand   CredentialSource = "command"
+	CredentialSourceNone      CredentialSource = "none"
 )
 
-func ResolveAPIKey(flagValue, envValue, configValue string) (string, CredentialSource) {
+// Credential is a resolved API key. Profile names the config profile that supplied it
+// and is empty for keys passed by flag or environment.
+type Credential struct {
+	Key     string
+	Source  CredentialSource
+	Profile string
+}
+
+func ResolveAPIKey(flagValue, envValue, configValue, profile string) Credential {
 	if key := strings.TrimSpace(flagValue); key != "" {
-		return key, CredentialSourceFlag
+		return Credential{Key: key, Source: CredentialSourceFlag}
 	}
 
 	if key := strings.TrimSpace(envValue); key != "" {
-		return key, CredentialSourceEnv
+		return Credential{Key: key, Source: CredentialSourceEnv}
 	}
 
 	if key := strings.TrimSpace(configValue); key != "" {
-		return key, CredentialSourceConfig
+		return Credential{Key: key, Source: CredentialSourceConfig, Profile: profile}
 	}
 
-	return "", CredentialSourceNone
+	return Credential{Source: CredentialSourceNone}
 }
 
 type Client struct {
 	baseURL    *url.URL
 	apiKey     string
 	httpClient *http.Client
+	retry      RetryPolicy
+	limiter    RateLimiter
+	sleep      func(ctx context.Context, d time.Duration) error
+	tracer     func(Trace)
 }
 
+// APIError is a non-2xx response. When the body is a Holded JSON error payload its
+// status, message, info and per-field errors are parsed into the remaining fields.
 type APIError struct {
 	StatusCode  int
 	BodySnippet string
+	Status      string
+	Message     string
+	Info        string
+	FieldErrors []FieldError
 }
 
 func (e *APIError) Error() string {
@@ -71,6 +93,7 @@ type Response struct {
 	StatusCode int
 	Headers    http.Header
 	Body       []byte
+	Attempts   int
 }
 
 func NewClient(baseURL, apiKey string, httpClient *http.Client) (*Client, error) {
@@ -91,15 +114,31 @@ func NewClient(baseURL, apiKey string, httpClient *http.Client) (*Client, error)
 		baseURL:    u,
 		apiKey:     strings.TrimSpace(apiKey),
 		httpClient: httpClient,
+		sleep:      sleepContext,
 	}, nil
 }
 
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+func (c *Client) SetRateLimiter(limiter RateLimiter) {
+	c.limiter = limiter
+}
+
 func (c *Client) Ping(ctx context.Context, path string) (int, error) {
 	resp, err := c.Do(ctx, Request{Method: http.MethodGet, Path: path})
 	return resp.StatusCode, err
 }
 
+// Do sends the request and returns the buffered response. The body is read within each
+// attempt, so a response cut short is retried like any other transport failure.
 func (c *Client) Do(ctx context.Context, request Request) (Response, error) {
+	_, response, err := c.send(ctx, request, true)
+	return response, err
+}
+
+func normalizeRequest(request Request) (string, string) {
 	method := strings.ToUpper(strings.TrimSpace(request.Method))
 	if method == "" {
 		method = http.MethodGet
@@ -110,9 +149,56 @@ func (c *Client) Do(ctx context.Context, request Request) (Response, error) {
 		path = "/"
 	}
 
+	return method, path
+}
+
+// send performs the request with rate limiting and retries. With buffer set, a 2xx body
+// is read into Response.Body and no *http.Response is returned; otherwise the body of
+// the returned *http.Response is left open for the caller to read and close.
+func (c *Client) send(ctx context.Context, request Request, buffer bool) (*http.Response, Response, error) {
+	method, path := normalizeRequest(request)
+
+	maxAttempts := c.retry.MaxAttempts
+	if maxAttempts < 1 || (!c.retry.RetryNonIdempotent && !isIdempotent(method)) {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, Response{Attempts: attempt - 1}, fmt.Errorf("waiting for rate limiter: %w", err)
+			}
+		}
+
+		resp, response, err := c.sendOnce(ctx, method, path, request, attempt, buffer)
+		response.Attempts = attempt
+		if err == nil {
+			return resp, response, nil
+		}
+		if attempt >= maxAttempts || ctx.Err() != nil {
+			return nil, response, err
+		}
+
+		wait, retryable := c.retry.backoff(attempt, response, err)
+		if !retryable {
+			return nil, response, err
+		}
+		if sleepErr := c.sleep(ctx, wait); sleepErr != nil {
+			return nil, response, err
+		}
+	}
+}
+
+// BuildRequest returns the HTTP request Do would send for request, without sending it.
+func (c *Client) BuildRequest(ctx context.Context, request Request) (*http.Request, error) {
+	method, path := normalizeRequest(request)
+	return c.buildRequest(ctx, method, path, request)
+}
+
+func (c *Client) buildRequest(ctx context.Context, method, path string, request Request) (*http.Request, error) {
 	req, err := c.newRequest(ctx, method, path, request.Query, request.Body)
 	if err != nil {
-		return Response{}, err
+		return nil, err
 	}
 
 	for key, value := range request.Headers {
@@ -126,23 +212,56 @@ func (c *Client) Do(ctx context.Context, request Request) (Response, error) {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	return req, nil
+}
+
+func (c *Client) sendOnce(ctx context.Context, method, path string, request Request, attempt int, buffer bool) (resp *http.Response, response Response, err error) {
+	req, err := c.buildRequest(ctx, method, path, request)
 	if err != nil {
-		return Response{}, err
+		return nil, Response{}, err
 	}
-	defer resp.Body.Close()
 
-	body, readErr := io.ReadAll(resp.Body)
-	if readErr != nil {
-		return Response{StatusCode: resp.StatusCode, Headers: resp.Header}, fmt.Errorf("reading holded response: %w", readErr)
+	if c.tracer != nil {
+		recorder, traceCtx := newTimingRecorder(req.Context())
+		req = req.WithContext(traceCtx)
+		defer func() {
+			emit := func() { c.tracer(newTrace(req, attempt, len(request.Body), recorder.finish(), response, err)) }
+			// A streamed body is still being read: time the attempt until it is closed.
+			if resp != nil {
+				resp.Body = &tracedBody{ReadCloser: resp.Body, done: emit}
+				return
+			}
+			emit()
+		}()
 	}
 
-	response := Response{StatusCode: resp.StatusCode, Headers: resp.Header, Body: body}
+	resp, err = c.httpClient.Do(req)
+	if err != nil {
+		return nil, Response{}, err
+	}
+
+	response = Response{StatusCode: resp.StatusCode, Headers: resp.Header}
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return response, &APIError{StatusCode: resp.StatusCode, BodySnippet: cleanSnippet(string(body))}
+		defer resp.Body.Close()
+
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, response, fmt.Errorf("reading holded response: %w", readErr)
+		}
+		response.Body = body
+		return nil, response, newAPIError(resp.StatusCode, body)
+	}
+	if !buffer {
+		return resp, response, nil
 	}
 
-	return response, nil
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, response, fmt.Errorf("reading holded response: %w", err)
+	}
+	response.Body = body
+	return nil, response, nil
 }
 
 func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body []byte) (*http.Request, error) {
@@ -172,7 +291,7 @@ func (c *Client) newRequest(ctx context.Context, method, path string, query url.
 
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("key", c.apiKey)
+	req.Header.Set(APIKeyHeader, c.apiKey)
 
 	return req, nil
 }
@@ -13,12 +13,13 @@ func TestResolveAPIKeyPriority(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name       string
-		flagValue  string
-		envValue   string
-		cfgValue   string
-		wantKey    string
-		wantSource CredentialSource
+		name        string
+		flagValue   string
+		envValue    string
+		cfgValue    string
+		wantKey     string
+		wantSource  CredentialSource
+		wantProfile string
 	}{
 		{
 			name:       "flag has priority",
@@ -36,10 +37,11 @@ func TestResolveAPIKeyPriority(t *testing.T) {
 			wantSource: CredentialSourceEnv,
 		},
 		{
-			name:       "config fallback",
-			cfgValue:   "cfg-key",
-			wantKey:    "cfg-key",
-			wantSource: CredentialSourceConfig,
+			name:        "config fallback",
+			cfgValue:    "cfg-key",
+			wantKey:     "cfg-key",
+			wantSource:  CredentialSourceConfig,
+			wantProfile: "acme",
 		},
 		{
 			name:       "none",
@@ -53,12 +55,15 @@ func TestResolveAPIKeyPriority(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			gotKey, gotSource := ResolveAPIKey(tt.flagValue, tt.envValue, tt.cfgValue)
-			if gotKey != tt.wantKey {
-				t.Fatalf("ResolveAPIKey() key = %q, want %q", gotKey, tt.wantKey)
+			got := ResolveAPIKey(tt.flagValue, tt.envValue, tt.cfgValue, "acme")
+			if got.Key != tt.wantKey {
+				t.Fatalf("ResolveAPIKey() key = %q, want %q", got.Key, tt.wantKey)
+			}
+			if got.Source != tt.wantSource {
+				t.Fatalf("ResolveAPIKey() source = %q, want %q", got.Source, tt.wantSource)
 			}
-			if gotSource != tt.wantSource {
-				t.Fatalf("ResolveAPIKey() source = %q, want %q", gotSource, tt.wantSource)
+			if got.Profile != tt.wantProfile {
+				t.Fatalf("ResolveAPIKey() profile = %q, want %q", got.Profile, tt.wantProfile)
 			}
 		})
 	}
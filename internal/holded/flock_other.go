@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package holded
+
+import (
+	"errors"
+	"os"
+)
+
+var errLockUnsupported = errors.New("file locking is not supported on this platform; use a per-process rate limit")
+
+func tryLockFile(*os.File) (bool, error) {
+	return false, errLockUnsupported
+}
+
+func unlockFile(*os.File) error {
+	return errLockUnsupported
+}
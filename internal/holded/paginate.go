@@ -0,0 +1,101 @@
+package holded
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	PageParam     = "page"
+	PageSizeParam = "limit"
+)
+
+// ErrNotArray is returned by ForEachPage when a page is not a JSON array.
+var ErrNotArray = errors.New("response is not a JSON array")
+
+// PageOptions controls ForEachPage. Zero values mean no page limit and the API default page size.
+type PageOptions struct {
+	MaxPages int
+	PageSize int
+}
+
+// PageStats summarizes the pages fetched by ForEachPage.
+type PageStats struct {
+	Pages      int
+	Items      int
+	Attempts   int
+	StatusCode int
+}
+
+// ForEachPage requests consecutive pages (starting at the request's page query value or 1) and calls fn
+// for every item as it is decoded. It stops on an empty page, a page shorter than PageSize, a page that
+// starts with the same item as the previous one (endpoints that ignore the page parameter) or after
+// MaxPages pages.
+func (c *Client) ForEachPage(ctx context.Context, request Request, opts PageOptions, fn func(item json.RawMessage) error) (PageStats, error) {
+	var stats PageStats
+
+	page := 1
+	if start, err := strconv.Atoi(request.Query.Get(PageParam)); err == nil && start > 0 {
+		page = start
+	}
+
+	var previousFirst json.RawMessage
+	for {
+		query := make(url.Values, len(request.Query)+2)
+		for key, values := range request.Query {
+			query[key] = append([]string(nil), values...)
+		}
+		query.Set(PageParam, strconv.Itoa(page))
+		if opts.PageSize > 0 {
+			query.Set(PageSizeParam, strconv.Itoa(opts.PageSize))
+		}
+
+		pageRequest := request
+		pageRequest.Query = query
+
+		var first json.RawMessage
+		count := 0
+		repeated := false
+		response, err := c.stream(ctx, pageRequest, true, func(index int, item json.RawMessage) error {
+			if index == 0 {
+				if previousFirst != nil && bytes.Equal(item, previousFirst) {
+					repeated = true
+					return errStopStream
+				}
+				first = item
+			}
+			count++
+			return fn(item)
+		})
+		stats.Attempts += response.Attempts
+		stats.StatusCode = response.StatusCode
+		if errors.Is(err, ErrNotArray) {
+			return stats, fmt.Errorf("page %d: %w", page, err)
+		}
+		if err != nil && !errors.Is(err, errStopStream) {
+			return stats, err
+		}
+		if repeated {
+			return stats, nil
+		}
+
+		// The empty page that ends pagination is not counted.
+		if count == 0 {
+			return stats, nil
+		}
+		stats.Pages++
+		stats.Items += count
+
+		if (opts.PageSize > 0 && count < opts.PageSize) || (opts.MaxPages > 0 && stats.Pages >= opts.MaxPages) {
+			return stats, nil
+		}
+
+		previousFirst = first
+		page++
+	}
+}
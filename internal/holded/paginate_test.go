@@ -0,0 +1,133 @@
+package holded
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestForEachPage(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]string{
+		"1": `[{"id":"a"},{"id":"b"}]`,
+		"2": `[{"id":"c"},{"id":"d"}]`,
+		"3": `[{"id":"e"}]`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("limit"); got != "2" {
+			t.Fatalf("limit = %q, want 2", got)
+		}
+		if got := r.URL.Query().Get("type"); got != "client" {
+			t.Fatalf("type = %q, want client", got)
+		}
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("page")]))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var ids []string
+	stats, err := client.ForEachPage(context.Background(), Request{
+		Path:  "/contacts",
+		Query: map[string][]string{"type": {"client"}},
+	}, PageOptions{PageSize: 2}, func(item json.RawMessage) error {
+		var v struct{ ID string }
+		_ = json.Unmarshal(item, &v)
+		ids = append(ids, v.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachPage() error = %v", err)
+	}
+	if stats.Pages != 3 || stats.Items != 5 || stats.Attempts != 3 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if len(ids) != 5 || ids[4] != "e" {
+		t.Fatalf("ids = %v", ids)
+	}
+}
+
+func TestForEachPageStopsOnRepeatedPageAndMaxPages(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Ignores the page parameter.
+		_, _ = w.Write([]byte(`[{"id":"a"}]`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	noop := func(item json.RawMessage) error { return nil }
+
+	stats, err := client.ForEachPage(context.Background(), Request{Path: "/contacts"}, PageOptions{}, noop)
+	if err != nil {
+		t.Fatalf("ForEachPage() error = %v", err)
+	}
+	if stats.Pages != 1 || stats.Items != 1 {
+		t.Fatalf("stats = %+v, want a single page", stats)
+	}
+
+	stats, err = client.ForEachPage(context.Background(), Request{Path: "/contacts"}, PageOptions{MaxPages: 1}, noop)
+	if err != nil || stats.Pages != 1 {
+		t.Fatalf("stats = %+v, err = %v", stats, err)
+	}
+}
+
+func TestForEachPageDoesNotCountEmptyPage(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte(`[{"id":"a"},{"id":"b"}]`))
+		case "2":
+			_, _ = w.Write([]byte(`[{"id":"c"},{"id":"d"}]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	stats, err := client.ForEachPage(context.Background(), Request{Path: "/contacts"}, PageOptions{PageSize: 2}, func(item json.RawMessage) error { return nil })
+	if err != nil {
+		t.Fatalf("ForEachPage() error = %v", err)
+	}
+	if stats.Pages != 2 || stats.Items != 4 || stats.Attempts != 3 {
+		t.Fatalf("stats = %+v, want 2 pages, 4 items, 3 attempts", stats)
+	}
+}
+
+func TestForEachPageRejectsObjects(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"a"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.ForEachPage(context.Background(), Request{Path: "/contacts/a"}, PageOptions{}, func(item json.RawMessage) error { return nil })
+	if !errors.Is(err, ErrNotArray) {
+		t.Fatalf("err = %v, want ErrNotArray", err)
+	}
+}
@@ -0,0 +1,85 @@
+package holded
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ErrNoPayload is returned by BinaryPayload when a JSON response carries no base64 file data.
+var ErrNoPayload = errors.New("response has no binary payload")
+
+// BinaryPayload returns the file carried by a response and its content type. Non-JSON bodies
+// (application/pdf, image/png, ...) are returned as-is. JSON bodies must hold the file as base64,
+// either in a "data" field as Holded does for PDFs or as a bare JSON string; data: URIs are accepted.
+func (r Response) BinaryPayload() ([]byte, string, error) {
+	contentType := r.Headers.Get("Content-Type")
+	if !isJSONContentType(contentType, r.Body) {
+		if contentType == "" {
+			contentType = http.DetectContentType(r.Body)
+		}
+		return r.Body, contentType, nil
+	}
+
+	var encoded string
+	if err := json.Unmarshal(r.Body, &encoded); err != nil {
+		var envelope struct {
+			Data *string `json:"data"`
+		}
+		if err := json.Unmarshal(r.Body, &envelope); err != nil || envelope.Data == nil {
+			return nil, "", ErrNoPayload
+		}
+		encoded = *envelope.Data
+	}
+
+	declaredType := ""
+	if rest, ok := strings.CutPrefix(encoded, "data:"); ok {
+		header, data, found := strings.Cut(rest, ",")
+		if !found || !strings.HasSuffix(header, ";base64") {
+			return nil, "", ErrNoPayload
+		}
+		declaredType = strings.TrimSuffix(header, ";base64")
+		encoded = data
+	}
+
+	payload, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, "", ErrNoPayload
+	}
+
+	if declaredType == "" {
+		declaredType = http.DetectContentType(payload)
+	}
+	return payload, declaredType, nil
+}
+
+func isJSONContentType(contentType string, body []byte) bool {
+	if contentType == "" {
+		return json.Valid(bytes.TrimSpace(body))
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == ' ' {
+			return -1
+		}
+		return r
+	}, value)
+
+	payload, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return base64.RawStdEncoding.DecodeString(value)
+	}
+	return payload, nil
+}
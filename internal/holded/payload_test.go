@@ -0,0 +1,50 @@
+package holded
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestResponseBinaryPayload(t *testing.T) {
+	t.Parallel()
+
+	pdf := []byte("%PDF-1.4\n%test document")
+	encoded := base64.StdEncoding.EncodeToString(pdf)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+		wantType    string
+	}{
+		{name: "raw pdf", contentType: "application/pdf", body: string(pdf), want: string(pdf), wantType: "application/pdf"},
+		{name: "data field", contentType: "application/json; charset=utf-8", body: `{"status":1,"data":"` + encoded + `"}`, want: string(pdf), wantType: "application/pdf"},
+		{name: "bare string", contentType: "application/json", body: `"` + encoded + `"`, want: string(pdf), wantType: "application/pdf"},
+		{name: "data uri", body: `{"data":"data:image/png;base64,` + base64.StdEncoding.EncodeToString([]byte("png")) + `"}`, want: "png", wantType: "image/png"},
+	}
+	for _, tt := range tests {
+		headers := http.Header{}
+		if tt.contentType != "" {
+			headers.Set("Content-Type", tt.contentType)
+		}
+
+		payload, contentType, err := Response{Headers: headers, Body: []byte(tt.body)}.BinaryPayload()
+		if err != nil {
+			t.Fatalf("%s: BinaryPayload() error = %v", tt.name, err)
+		}
+		if string(payload) != tt.want {
+			t.Fatalf("%s: payload = %q, want %q", tt.name, payload, tt.want)
+		}
+		if contentType != tt.wantType {
+			t.Fatalf("%s: content type = %q, want %q", tt.name, contentType, tt.wantType)
+		}
+	}
+
+	_, _, err := Response{Headers: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"status":0,"info":"missing"}`)}.BinaryPayload()
+	if !errors.Is(err, ErrNoPayload) {
+		t.Fatalf("expected ErrNoPayload, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package holded
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const lockRetryInterval = 10 * time.Millisecond
+
+// RateLimiter blocks until the next request may be sent.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// bucket is the token-bucket state shared by the in-process and file-backed limiters.
+type bucket struct {
+	Tokens  float64   `json:"tokens"`
+	Updated time.Time `json:"updated"`
+}
+
+// take refills the bucket up to burst and consumes one token, returning how long to wait when none is available.
+func (b *bucket) take(now time.Time, rate float64, burst int) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+
+	capacity := float64(burst)
+	if b.Updated.IsZero() || b.Tokens > capacity {
+		b.Tokens = capacity
+	} else if elapsed := now.Sub(b.Updated).Seconds(); elapsed > 0 {
+		b.Tokens = min(capacity, b.Tokens+elapsed*rate)
+	}
+	b.Updated = now
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return 0
+	}
+	return time.Duration((1 - b.Tokens) / rate * float64(time.Second))
+}
+
+// TokenBucket limits requests made by a single process.
+type TokenBucket struct {
+	mu    sync.Mutex
+	rate  float64
+	burst int
+	state bucket
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewTokenBucket allows rate requests per second with bursts of up to burst requests.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:  rate,
+		burst: max(burst, 1),
+		now:   time.Now,
+		sleep: sleepContext,
+	}
+}
+
+func (l *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		wait := l.state.take(l.now(), l.rate, l.burst)
+		l.mu.Unlock()
+
+		if wait == 0 {
+			return nil
+		}
+		if err := l.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// SharedTokenBucket limits requests across processes by keeping the bucket in a
+// state file guarded by an OS file lock, so concurrent CLI invocations share one
+// budget. The kernel releases the lock when a process exits, so a crash never leaves
+// it held.
+type SharedTokenBucket struct {
+	path  string
+	rate  float64
+	burst int
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewSharedTokenBucket stores the bucket state in path (and path+".lock").
+func NewSharedTokenBucket(path string, rate float64, burst int) *SharedTokenBucket {
+	return &SharedTokenBucket{
+		path:  path,
+		rate:  rate,
+		burst: max(burst, 1),
+		now:   time.Now,
+		sleep: sleepContext,
+	}
+}
+
+func (l *SharedTokenBucket) Wait(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("creating rate limit directory: %w", err)
+	}
+
+	for {
+		wait, err := l.take(ctx)
+		if err != nil {
+			return err
+		}
+		if wait == 0 {
+			return nil
+		}
+		if err := l.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *SharedTokenBucket) take(ctx context.Context) (time.Duration, error) {
+	unlock, err := l.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	var state bucket
+	if b, err := os.ReadFile(l.path); err == nil {
+		// A corrupt state file resets to a full bucket.
+		_ = json.Unmarshal(b, &state)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("reading rate limit state: %w", err)
+	}
+
+	wait := state.take(l.now(), l.rate, l.burst)
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(l.path, b, 0o600); err != nil {
+		return 0, fmt.Errorf("writing rate limit state: %w", err)
+	}
+
+	return wait, nil
+}
+
+// lock takes an exclusive lock on path+".lock", which is never removed: deleting a
+// lock file lets two processes lock different files at once.
+func (l *SharedTokenBucket) lock(ctx context.Context) (func(), error) {
+	f, err := os.OpenFile(l.path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening rate limit lock: %w", err)
+	}
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("acquiring rate limit lock: %w", err)
+		}
+		if locked {
+			return func() {
+				_ = unlockFile(f)
+				f.Close()
+			}, nil
+		}
+
+		if err := sleepContext(ctx, lockRetryInterval); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+}
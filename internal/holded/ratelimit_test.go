@@ -0,0 +1,131 @@
+package holded
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewTokenBucket(2, 2)
+	limiter.now = clock.Now
+	limiter.sleep = clock.Sleep
+
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	// Two requests fit in the burst, the next two wait half a second each at 2 rps.
+	if len(clock.waits) != 2 {
+		t.Fatalf("waits = %v, want 2 waits", clock.waits)
+	}
+	for _, wait := range clock.waits {
+		if wait != 500*time.Millisecond {
+			t.Fatalf("wait = %s, want 500ms", wait)
+		}
+	}
+}
+
+func TestSharedTokenBucketSharesBudget(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+
+	first := NewSharedTokenBucket(path, 1, 1)
+	first.now = clock.Now
+	first.sleep = clock.Sleep
+	second := NewSharedTokenBucket(path, 1, 1)
+	second.now = clock.Now
+	second.sleep = clock.Sleep
+
+	if err := first.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if err := second.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	if len(clock.waits) != 1 || clock.waits[0] != time.Second {
+		t.Fatalf("waits = %v, want a single 1s wait for the second process", clock.waits)
+	}
+}
+
+func TestSharedTokenBucketLockIsExclusive(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	first := NewSharedTokenBucket(path, 1, 1)
+	second := NewSharedTokenBucket(path, 1, 1)
+
+	unlock, err := first.lock(context.Background())
+	if err != nil {
+		t.Fatalf("lock() error = %v", err)
+	}
+
+	// An old lock file is not treated as stale while its holder is alive.
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := second.lock(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second lock() error = %v, want a timeout while the first holds it", err)
+	}
+
+	unlock()
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Fatalf("lock file removed on unlock: %v", err)
+	}
+	unlockSecond, err := second.lock(context.Background())
+	if err != nil {
+		t.Fatalf("second lock() after unlock error = %v", err)
+	}
+	unlockSecond()
+}
+
+func TestClientUsesRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient("http://127.0.0.1:0", "test-key", nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	limiter := NewTokenBucket(1, 1)
+	limiter.state = bucket{Tokens: 0, Updated: time.Now()}
+	client.SetRateLimiter(limiter)
+
+	resp, err := client.Do(ctx, Request{Path: "/ping"})
+	if err == nil {
+		t.Fatalf("expected rate limiter error with cancelled context")
+	}
+	if resp.Attempts != 0 {
+		t.Fatalf("attempts = %d, want 0", resp.Attempts)
+	}
+}
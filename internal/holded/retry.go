@@ -0,0 +1,136 @@
+package holded
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxWait   = 30 * time.Second
+)
+
+// RetryPolicy controls how Client.Do retries transient failures (429, 502, 503, 504 and network errors).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the first backoff delay; it doubles on every retry.
+	BaseDelay time.Duration
+	// MaxWait caps a single wait, including waits requested through Retry-After.
+	MaxWait time.Duration
+	// RetryNonIdempotent also retries POST and PATCH requests.
+	RetryNonIdempotent bool
+}
+
+func (p RetryPolicy) backoff(attempt int, response Response, err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if !isRetryableStatus(apiErr.StatusCode) {
+			return 0, false
+		}
+	} else if !isTransient(err) {
+		return 0, false
+	}
+
+	maxWait := p.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultRetryMaxWait
+	}
+
+	if wait, ok := parseRetryAfter(response.Headers.Get("Retry-After"), time.Now()); ok {
+		return min(wait, maxWait), true
+	}
+
+	base := p.BaseDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+
+	delay := base << (attempt - 1)
+	if delay <= 0 || delay > maxWait {
+		delay = maxWait
+	}
+
+	// Equal jitter keeps at least half of the delay while spreading concurrent clients.
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)), true
+}
+
+// isTransient reports whether err is a transport failure worth retrying: network errors
+// and connections reset or closed mid-response. Cancellation, invalid requests and other
+// local failures are not retried.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	// url.Parse errors are *url.Error, which also satisfies net.Error.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Op == "parse" {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		wait := at.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,217 @@
+package holded
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxWait: 5 * time.Second})
+
+	var waits []time.Duration
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	resp, err := client.Do(context.Background(), Request{Method: http.MethodGet, Path: "/ping"})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.Attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", resp.Attempts)
+	}
+	if len(waits) != 2 {
+		t.Fatalf("waits = %v, want 2 waits", waits)
+	}
+	if waits[0] != 5*time.Second {
+		t.Fatalf("Retry-After wait = %s, want capped 5s", waits[0])
+	}
+	if waits[1] < time.Second || waits[1] > 2*time.Second {
+		t.Fatalf("backoff wait = %s, want between 1s and 2s", waits[1])
+	}
+}
+
+func TestClientDoesNotRetryNonIdempotentByDefault(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3})
+	client.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	resp, err := client.Do(context.Background(), Request{Method: http.MethodPost, Path: "/contacts", Body: []byte(`{}`)})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if calls.Load() != 1 || resp.Attempts != 1 {
+		t.Fatalf("calls = %d, attempts = %d, want 1", calls.Load(), resp.Attempts)
+	}
+
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, RetryNonIdempotent: true})
+	resp, _ = client.Do(context.Background(), Request{Method: http.MethodPost, Path: "/contacts", Body: []byte(`{}`)})
+	if resp.Attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", resp.Attempts)
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3})
+
+	if _, err := client.Ping(context.Background(), "/ping"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestClientDoesNotRetryLocalErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3})
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		t.Errorf("unexpected retry wait %s", d)
+		return nil
+	}
+
+	for _, request := range []Request{
+		{Method: http.MethodGet, Path: "/contacts\x00"},
+		{Method: "BAD METHOD", Path: "/contacts"},
+	} {
+		resp, err := client.Do(context.Background(), request)
+		if err == nil {
+			t.Fatalf("Do(%+v) expected error", request)
+		}
+		if resp.Attempts != 1 {
+			t.Fatalf("Do(%+v) attempts = %d, want 1", request, resp.Attempts)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("calls = %d, want 0", calls.Load())
+	}
+}
+
+func TestClientRetriesConnectionErrors(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 2})
+	client.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	resp, err := client.Do(context.Background(), Request{Method: http.MethodGet, Path: "/contacts"})
+	if err == nil || resp.Attempts != 2 {
+		t.Fatalf("attempts = %d, err = %v; want 2 attempts on a refused connection", resp.Attempts, err)
+	}
+}
+
+func TestClientRetriesTruncatedResponses(t *testing.T) {
+	t.Parallel()
+
+	const body = `[{"id":"c1"},{"id":"c2"}]`
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		if calls.Add(1) == 1 {
+			// Promise the full body but close the connection halfway through it.
+			_, _ = w.Write([]byte(body[:10]))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 2})
+	client.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	resp, err := client.Do(context.Background(), Request{Method: http.MethodGet, Path: "/contacts"})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.Attempts != 2 || string(resp.Body) != body {
+		t.Fatalf("attempts = %d, body = %q; want the second, complete response", resp.Attempts, resp.Body)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	if wait, ok := parseRetryAfter("3", now); !ok || wait != 3*time.Second {
+		t.Fatalf("parseRetryAfter(seconds) = %s, %v", wait, ok)
+	}
+	if wait, ok := parseRetryAfter("Thu, 01 Oct 2026 12:00:10 GMT", now); !ok || wait != 10*time.Second {
+		t.Fatalf("parseRetryAfter(date) = %s, %v", wait, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Fatalf("expected invalid Retry-After to be ignored")
+	}
+}
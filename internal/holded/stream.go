@@ -0,0 +1,89 @@
+package holded
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+)
+
+// errStopStream ends a stream early without reporting an error.
+var errStopStream = errors.New("stop stream")
+
+// StreamItems sends the request and calls fn for every element of a JSON array
+// response as it is decoded, without buffering the whole body. Any other JSON
+// value is passed to fn as a single item; an empty body produces no items.
+func (c *Client) StreamItems(ctx context.Context, request Request, fn func(item json.RawMessage) error) (Response, error) {
+	return c.stream(ctx, request, false, func(_ int, item json.RawMessage) error {
+		return fn(item)
+	})
+}
+
+func (c *Client) stream(ctx context.Context, request Request, requireArray bool, fn func(index int, item json.RawMessage) error) (Response, error) {
+	resp, response, err := c.send(ctx, request, false)
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	first, err := peekNonSpace(reader)
+	if errors.Is(err, io.EOF) {
+		if requireArray {
+			return response, ErrNotArray
+		}
+		return response, nil
+	}
+	if err != nil {
+		return response, fmt.Errorf("reading holded response: %w", err)
+	}
+
+	dec := json.NewDecoder(reader)
+	if first != '[' {
+		if requireArray {
+			return response, ErrNotArray
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return response, fmt.Errorf("decoding holded response: %w", err)
+		}
+		return response, fn(0, value)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return response, fmt.Errorf("decoding holded response: %w", err)
+	}
+	for i := 0; dec.More(); i++ {
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			return response, fmt.Errorf("decoding holded response: %w", err)
+		}
+		if err := fn(i, item); err != nil {
+			return response, err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return response, fmt.Errorf("decoding holded response: %w", err)
+	}
+
+	return response, nil
+}
+
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			return b[0], nil
+		}
+		if _, err := reader.Discard(1); err != nil {
+			return 0, err
+		}
+	}
+}
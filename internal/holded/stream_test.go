@@ -0,0 +1,86 @@
+package holded
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamItems(t *testing.T) {
+	t.Parallel()
+
+	bodies := map[string]string{
+		"/array":  " [ {\"id\":\"a\"}, {\"id\":\"b\"}, 3 ]",
+		"/object": `{"status":1}`,
+		"/empty":  "",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "/array", want: []string{`{"id":"a"}`, `{"id":"b"}`, `3`}},
+		{path: "/object", want: []string{`{"status":1}`}},
+		{path: "/empty", want: nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		response, err := client.StreamItems(context.Background(), Request{Path: tt.path}, func(item json.RawMessage) error {
+			got = append(got, string(item))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamItems(%s) error = %v", tt.path, err)
+		}
+		if response.StatusCode != http.StatusOK || response.Attempts != 1 {
+			t.Fatalf("StreamItems(%s) response = %+v", tt.path, response)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("StreamItems(%s) items = %v, want %v", tt.path, got, tt.want)
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Fatalf("StreamItems(%s) items[%d] = %s, want %s", tt.path, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestStreamItemsAPIError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"info":"not found"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	called := false
+	_, err = client.StreamItems(context.Background(), Request{Path: "/missing"}, func(item json.RawMessage) error {
+		called = true
+		return nil
+	})
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected APIError 404, got %v", err)
+	}
+	if called {
+		t.Fatalf("callback must not run for error responses")
+	}
+}
@@ -0,0 +1,151 @@
+package holded
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Trace describes one HTTP attempt made by the client. The API key header is masked and
+// ErrorBody holds the full, untruncated body of non-2xx responses.
+type Trace struct {
+	Attempt         int
+	Method          string
+	URL             string
+	RequestHeaders  http.Header
+	BodySize        int
+	StatusCode      int
+	ResponseHeaders http.Header
+	ErrorBody       string
+	Err             string
+	Timing          Timing
+}
+
+// Timing is the httptrace breakdown of an attempt. Phases that did not happen (for example
+// DNS and connect on a reused connection) are zero, and Total ends once the body is read.
+type Timing struct {
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
+	Total     time.Duration
+	Reused    bool
+}
+
+// SetTracer registers fn to be called after every attempt, including retried ones.
+func (c *Client) SetTracer(fn func(Trace)) {
+	c.tracer = fn
+}
+
+func newTrace(req *http.Request, attempt, bodySize int, timing Timing, response Response, err error) Trace {
+	trace := Trace{
+		Attempt:         attempt,
+		Method:          req.Method,
+		URL:             req.URL.String(),
+		RequestHeaders:  maskedHeaders(req.Header),
+		BodySize:        bodySize,
+		StatusCode:      response.StatusCode,
+		ResponseHeaders: response.Headers,
+		Timing:          timing,
+	}
+	if response.StatusCode != 0 && (response.StatusCode < 200 || response.StatusCode > 299) {
+		trace.ErrorBody = string(response.Body)
+	}
+	if err != nil {
+		trace.Err = err.Error()
+	}
+	return trace
+}
+
+// timingRecorder collects httptrace callbacks, which may run on transport goroutines.
+type timingRecorder struct {
+	mu                     sync.Mutex
+	start                  time.Time
+	dnsStart, connectStart time.Time
+	tlsStart               time.Time
+	timing                 Timing
+}
+
+func newTimingRecorder(ctx context.Context) (*timingRecorder, context.Context) {
+	r := &timingRecorder{start: time.Now()}
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.record(func(now time.Time) { r.timing.Reused = info.Reused })
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			r.record(func(now time.Time) { r.dnsStart = now })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.record(func(now time.Time) { r.timing.DNS = since(r.dnsStart, now) })
+		},
+		ConnectStart: func(string, string) {
+			r.record(func(now time.Time) {
+				if r.connectStart.IsZero() {
+					r.connectStart = now
+				}
+			})
+		},
+		ConnectDone: func(string, string, error) {
+			r.record(func(now time.Time) { r.timing.Connect = since(r.connectStart, now) })
+		},
+		TLSHandshakeStart: func() {
+			r.record(func(now time.Time) { r.tlsStart = now })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.record(func(now time.Time) { r.timing.TLS = since(r.tlsStart, now) })
+		},
+		GotFirstResponseByte: func() {
+			r.record(func(now time.Time) { r.timing.FirstByte = now.Sub(r.start) })
+		},
+	}
+	return r, httptrace.WithClientTrace(ctx, trace)
+}
+
+// tracedBody reports a streamed attempt once its body has been read and closed, so
+// Timing.Total covers the whole response.
+type tracedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+func (r *timingRecorder) record(fn func(now time.Time)) {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(now)
+}
+
+func (r *timingRecorder) finish() Timing {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	timing := r.timing
+	timing.Total = time.Since(r.start)
+	return timing
+}
+
+func since(start, now time.Time) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+	return now.Sub(start)
+}
+
+// maskedHeaders copies h with the API key replaced by MaskKey.
+func maskedHeaders(h http.Header) http.Header {
+	masked := h.Clone()
+	if key := masked.Get(APIKeyHeader); key != "" {
+		masked.Set(APIKeyHeader, MaskKey(key))
+	}
+	return masked
+}
@@ -0,0 +1,92 @@
+package holded
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientTracerRecordsAttempts(t *testing.T) {
+	t.Parallel()
+
+	longBody := `{"error":"` + strings.Repeat("x", 300) + `"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(longBody))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "secret-api-key-1234", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var traces []Trace
+	client.SetTracer(func(trace Trace) { traces = append(traces, trace) })
+
+	_, err = client.Do(context.Background(), Request{Method: http.MethodPost, Path: "/contacts", Body: []byte(`{"name":"a"}`)})
+	if err == nil {
+		t.Fatal("Do() error = nil, want APIError")
+	}
+
+	if len(traces) != 1 {
+		t.Fatalf("traces = %d, want 1", len(traces))
+	}
+	trace := traces[0]
+	if trace.Attempt != 1 || trace.Method != http.MethodPost || trace.URL != srv.URL+"/contacts" || trace.BodySize != 12 {
+		t.Fatalf("trace = %+v", trace)
+	}
+	if got := trace.RequestHeaders.Get(APIKeyHeader); got != "****1234" {
+		t.Fatalf("key header = %q, want masked", got)
+	}
+	if trace.StatusCode != http.StatusBadRequest || trace.ResponseHeaders.Get("X-Request-Id") != "req-1" {
+		t.Fatalf("response = %d %v", trace.StatusCode, trace.ResponseHeaders)
+	}
+	if trace.ErrorBody != longBody {
+		t.Fatalf("error body has %d bytes, want the full %d", len(trace.ErrorBody), len(longBody))
+	}
+	if trace.Err == "" || trace.Timing.Total <= 0 || trace.Timing.FirstByte <= 0 {
+		t.Fatalf("trace = %+v", trace)
+	}
+}
+
+func TestClientTracerTimesWholeBody(t *testing.T) {
+	t.Parallel()
+
+	const delay = 50 * time.Millisecond
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"c1"},`))
+		w.(http.Flusher).Flush()
+		time.Sleep(delay)
+		_, _ = w.Write([]byte(`{"id":"c2"}]`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "test-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	var traces []Trace
+	client.SetTracer(func(trace Trace) { traces = append(traces, trace) })
+
+	if _, err := client.Do(context.Background(), Request{Path: "/contacts"}); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if _, err := client.StreamItems(context.Background(), Request{Path: "/contacts"}, func(json.RawMessage) error { return nil }); err != nil {
+		t.Fatalf("StreamItems() error = %v", err)
+	}
+
+	if len(traces) != 2 {
+		t.Fatalf("traces = %d, want 2", len(traces))
+	}
+	for _, trace := range traces {
+		if trace.Timing.Total < delay || trace.Timing.Total <= trace.Timing.FirstByte {
+			t.Fatalf("timing = %+v, want Total to include the %s spent reading the body", trace.Timing, delay)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package holded
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Key statuses reported by Verify.
+const (
+	KeyValid       = "valid"
+	KeyInvalid     = "invalid"
+	KeyUnreachable = "unreachable"
+)
+
+// APIProbe is a cheap read used to check whether a key can access one Holded API.
+type APIProbe struct {
+	API  string
+	Path string
+}
+
+// DefaultAPIProbes covers every Holded API with one inexpensive list endpoint.
+var DefaultAPIProbes = []APIProbe{
+	{API: "Invoice API", Path: DefaultPingPath},
+	{API: "CRM API", Path: "/api/crm/v1/funnels"},
+	{API: "Projects API", Path: "/api/projects/v1/projects"},
+	{API: "Team API", Path: "/api/team/v1/employees"},
+	{API: "Accounting API", Path: "/api/accounting/v1/chartofaccounts"},
+}
+
+// Verification is the outcome of Verify.
+type Verification struct {
+	Status     string
+	StatusCode int
+	Err        error
+	APIs       []APIAccess
+}
+
+// APIAccess reports whether a probe succeeded. StatusCode is zero when the request failed before a response.
+type APIAccess struct {
+	API        string
+	Path       string
+	Accessible bool
+	StatusCode int
+	Err        error
+}
+
+// Verify pings pingPath to classify the key as valid, invalid (401) or unreachable
+// (network errors and other failures), then, for valid keys, runs each probe. A 403 means
+// the key is valid but lacks access to the ping path's API, which its probe reports.
+func (c *Client) Verify(ctx context.Context, pingPath string, probes []APIProbe) Verification {
+	status, err := c.Ping(ctx, pingPath)
+	verification := Verification{Status: classifyKey(status, err), StatusCode: status}
+	if verification.Status != KeyValid {
+		verification.Err = err
+		return verification
+	}
+
+	verification.APIs = make([]APIAccess, 0, len(probes))
+	for _, probe := range probes {
+		access := APIAccess{API: probe.API, Path: probe.Path}
+		if probe.Path == pingPath {
+			access.StatusCode, access.Err = status, err
+		} else {
+			access.StatusCode, access.Err = c.Ping(ctx, probe.Path)
+		}
+		access.Accessible = access.Err == nil
+		verification.APIs = append(verification.APIs, access)
+	}
+
+	return verification
+}
+
+func classifyKey(status int, err error) string {
+	if err == nil {
+		return KeyValid
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch status {
+		case http.StatusUnauthorized:
+			return KeyInvalid
+		case http.StatusForbidden:
+			return KeyValid
+		}
+	}
+	return KeyUnreachable
+}
+
+// KeyFingerprint identifies a key without revealing it: the first 12 hex digits of its SHA-256.
+func KeyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// MaskKey hides all but the last four characters of a key (all of it for short keys).
+func MaskKey(key string) string {
+	key = strings.TrimSpace(key)
+	if len(key) <= 8 {
+		return strings.Repeat("*", len(key))
+	}
+	return strings.Repeat("*", 4) + key[len(key)-4:]
+}
@@ -0,0 +1,106 @@
+package holded
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("key") != "good-key":
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.HasPrefix(r.URL.Path, "/api/team/"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "good-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	got := client.Verify(context.Background(), DefaultPingPath, DefaultAPIProbes)
+	if got.Status != KeyValid {
+		t.Fatalf("Status = %q, want valid (err %v)", got.Status, got.Err)
+	}
+	if len(got.APIs) != len(DefaultAPIProbes) {
+		t.Fatalf("APIs = %+v", got.APIs)
+	}
+	for _, access := range got.APIs {
+		wantAccess := access.API != "Team API"
+		if access.Accessible != wantAccess {
+			t.Fatalf("%s accessible = %v, want %v", access.API, access.Accessible, wantAccess)
+		}
+	}
+
+	badClient, err := NewClient(srv.URL, "bad-key", srv.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if got := badClient.Verify(context.Background(), DefaultPingPath, DefaultAPIProbes); got.Status != KeyInvalid || len(got.APIs) != 0 {
+		t.Fatalf("bad key verification = %+v", got)
+	}
+
+	// A key without Invoice API access is still valid, and the other APIs are probed.
+	invoiceForbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/invoicing/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer invoiceForbidden.Close()
+
+	crmClient, err := NewClient(invoiceForbidden.URL, "crm-only-key", invoiceForbidden.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	got = crmClient.Verify(context.Background(), DefaultPingPath, DefaultAPIProbes)
+	if got.Status != KeyValid || got.Err != nil || len(got.APIs) != len(DefaultAPIProbes) {
+		t.Fatalf("403 verification = %+v", got)
+	}
+	for _, access := range got.APIs {
+		wantAccess := access.API != "Invoice API"
+		if access.Accessible != wantAccess {
+			t.Fatalf("%s accessible = %v, want %v", access.API, access.Accessible, wantAccess)
+		}
+		if !wantAccess && access.StatusCode != http.StatusForbidden {
+			t.Fatalf("Invoice API status = %d, want 403", access.StatusCode)
+		}
+	}
+
+	downClient, err := NewClient("http://127.0.0.1:1", "good-key", nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if got := downClient.Verify(context.Background(), DefaultPingPath, DefaultAPIProbes); got.Status != KeyUnreachable {
+		t.Fatalf("unreachable verification = %+v", got)
+	}
+}
+
+func TestKeyFingerprintAndMask(t *testing.T) {
+	t.Parallel()
+
+	fp := KeyFingerprint("abcdef0123456789")
+	if !strings.HasPrefix(fp, "sha256:") || len(fp) != len("sha256:")+12 || strings.Contains(fp, "abcdef0123") {
+		t.Fatalf("KeyFingerprint() = %q", fp)
+	}
+	if fp != KeyFingerprint(" abcdef0123456789 ") {
+		t.Fatalf("fingerprint should ignore surrounding whitespace")
+	}
+	if got := MaskKey("abcdef0123456789"); got != "****6789" {
+		t.Fatalf("MaskKey() = %q", got)
+	}
+	if got := MaskKey("short"); got != "*****" {
+		t.Fatalf("MaskKey(short) = %q", got)
+	}
+}
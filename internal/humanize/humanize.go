@@ -0,0 +1,223 @@
+// Package humanize renders Holded's Unix timestamps and raw amounts for people, and
+// converts ISO dates typed by people back into timestamps.
+package humanize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocale formats amounts as 1,234.50.
+const DefaultLocale = "en"
+
+// dateFields are Holded fields holding Unix timestamps in seconds. Any other field
+// whose name ends in "Date" is treated the same way.
+var dateFields = map[string]bool{
+	"date":      true,
+	"dueDate":   true,
+	"createdAt": true,
+	"updatedAt": true,
+	"paidAt":    true,
+	"startDate": true,
+	"endDate":   true,
+}
+
+// moneyFields are Holded fields holding amounts in the document currency. Any other
+// field whose name ends in "Total" or "Amount" is treated the same way. Item-level
+// "tax" and "discount" are percentages and are left alone.
+var moneyFields = map[string]bool{
+	"total":           true,
+	"subtotal":        true,
+	"price":           true,
+	"amount":          true,
+	"balance":         true,
+	"cost":            true,
+	"paymentsPending": true,
+	"paymentsRefunds": true,
+}
+
+// separators maps a language to its thousands and decimal separators.
+var separators = map[string][2]string{
+	"en": {",", "."},
+	"es": {".", ","},
+	"ca": {".", ","},
+	"de": {".", ","},
+	"it": {".", ","},
+	"pt": {".", ","},
+	"nl": {".", ","},
+	"fr": {" ", ","},
+}
+
+// Options controls Response. A nil Location means time.Local; an empty or unknown
+// Locale means DefaultLocale.
+type Options struct {
+	Location *time.Location
+	Locale   string
+}
+
+// IsDateField reports whether name holds a Unix timestamp in Holded payloads.
+func IsDateField(name string) bool {
+	return dateFields[name] || strings.HasSuffix(name, "Date")
+}
+
+// IsMoneyField reports whether name holds an amount in Holded payloads.
+func IsMoneyField(name string) bool {
+	return moneyFields[name] || strings.HasSuffix(name, "Total") || strings.HasSuffix(name, "Amount")
+}
+
+// ValidLocale reports whether locale (a language such as "es" or "es-ES") is supported.
+func ValidLocale(locale string) bool {
+	_, ok := separators[language(locale)]
+	return ok
+}
+
+// Response returns a copy of a decoded JSON value with date fields rendered as ISO
+// dates (2026-10-01, or RFC 3339 when the time is not midnight) and money fields as
+// localized amounts followed by the sibling "currency" code when there is one.
+func Response(value any, opts Options) any {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	switch v := value.(type) {
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = Response(item, opts)
+		}
+		return out
+	case map[string]any:
+		currency, _ := v["currency"].(string)
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			number, isNumber := item.(float64)
+			switch {
+			case isNumber && IsDateField(key) && number > 0:
+				out[key] = FormatDate(number, opts.Location)
+			case isNumber && IsMoneyField(key):
+				out[key] = Money(number, currency, opts.Locale)
+			default:
+				out[key] = Response(item, opts)
+			}
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// FormatDate renders Unix seconds in loc: a plain date at midnight, RFC 3339 otherwise.
+func FormatDate(seconds float64, loc *time.Location) string {
+	sec, frac := math.Modf(seconds)
+	t := time.Unix(int64(sec), int64(frac*1e9)).In(loc)
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// Money formats amount with two decimals and the locale's separators, followed by the
+// upper-cased currency code when one is given: Money(1234.5, "eur", "es") is "1.234,50 EUR".
+func Money(amount float64, currency, locale string) string {
+	seps, ok := separators[language(locale)]
+	if !ok {
+		seps = separators[DefaultLocale]
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	whole, cents, _ := strings.Cut(strconv.FormatFloat(amount, 'f', 2, 64), ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(seps[0])
+		}
+		b.WriteRune(digit)
+	}
+	b.WriteString(seps[1])
+	b.WriteString(cents)
+
+	if currency = strings.TrimSpace(currency); currency != "" {
+		b.WriteString(" ")
+		b.WriteString(strings.ToUpper(currency))
+	}
+	return b.String()
+}
+
+// inputLayouts are the date formats Body accepts, tried in order.
+var inputLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", time.DateOnly}
+
+// Body converts ISO date strings in date fields of a JSON request body to Unix seconds,
+// interpreting dates without an offset in loc. The body is returned unchanged when
+// nothing was converted; a string that is not a date is left for validation to report.
+func Body(body []byte, loc *time.Location) ([]byte, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body, nil
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %w", err)
+	}
+
+	if !convertDates(decoded, loc) {
+		return body, nil
+	}
+	return json.Marshal(decoded)
+}
+
+func convertDates(value any, loc *time.Location) bool {
+	changed := false
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			changed = convertDates(item, loc) || changed
+		}
+	case map[string]any:
+		for key, item := range v {
+			if s, ok := item.(string); ok && IsDateField(key) {
+				if t, ok := parseDate(s, loc); ok {
+					v[key] = t.Unix()
+					changed = true
+				}
+				continue
+			}
+			changed = convertDates(item, loc) || changed
+		}
+	}
+	return changed
+}
+
+func parseDate(value string, loc *time.Location) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range inputLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func language(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" {
+		return DefaultLocale
+	}
+	lang, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	return lang
+}
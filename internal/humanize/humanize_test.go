@@ -0,0 +1,107 @@
+package humanize
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResponse(t *testing.T) {
+	t.Parallel()
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	var input any
+	if err := json.Unmarshal([]byte(`[{
+		"id": "d1",
+		"date": 1759269600,
+		"dueDate": 1759314600,
+		"createdAt": 0,
+		"currency": "eur",
+		"total": 1234567.891,
+		"paymentsTotal": -10,
+		"items": [{"price": 100, "tax": 21, "units": 2}]
+	}]`), &input); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Response(input, Options{Location: madrid, Locale: "es-ES"})
+	want := []any{map[string]any{
+		"id":            "d1",
+		"date":          "2025-10-01",
+		"dueDate":       "2025-10-01T12:30:00+02:00",
+		"createdAt":     float64(0),
+		"currency":      "eur",
+		"total":         "1.234.567,89 EUR",
+		"paymentsTotal": "-10,00 EUR",
+		"items":         []any{map[string]any{"price": "100,00", "tax": float64(21), "units": float64(2)}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Response() = %#v\nwant %#v", got, want)
+	}
+}
+
+func TestMoney(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		amount   float64
+		currency string
+		locale   string
+		want     string
+	}{
+		{1234.5, "", "", "1,234.50"},
+		{1234.5, "eur", "es", "1.234,50 EUR"},
+		{999, "usd", "en-US", "999.00 USD"},
+		{-1000000, "", "fr", "-1 000 000,00"},
+		{0.005, "", "xx", "0.01"},
+	}
+	for _, tt := range tests {
+		if got := Money(tt.amount, tt.currency, tt.locale); got != tt.want {
+			t.Fatalf("Money(%v, %q, %q) = %q, want %q", tt.amount, tt.currency, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestBody(t *testing.T) {
+	t.Parallel()
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	got, err := Body([]byte(`{"contactId":"c1","date":"2026-10-01","dueDate":"2026-10-31T10:00:00Z","items":[{"name":"x","price":12.50}],"notes":"2026-10-01"}`), madrid)
+	if err != nil {
+		t.Fatalf("Body() error = %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("invalid body %s: %v", got, err)
+	}
+	if decoded["date"] != float64(time.Date(2026, 10, 1, 0, 0, 0, 0, madrid).Unix()) {
+		t.Fatalf("date = %v", decoded["date"])
+	}
+	if decoded["dueDate"] != float64(time.Date(2026, 10, 31, 10, 0, 0, 0, time.UTC).Unix()) {
+		t.Fatalf("dueDate = %v", decoded["dueDate"])
+	}
+	if decoded["notes"] != "2026-10-01" {
+		t.Fatalf("notes = %v, want it untouched", decoded["notes"])
+	}
+	if items := decoded["items"].([]any); items[0].(map[string]any)["price"] != 12.5 {
+		t.Fatalf("items = %v", items)
+	}
+
+	unchanged := []byte(`{"date": 1759269600, "name": "x"}`)
+	if got, err := Body(unchanged, madrid); err != nil || string(got) != string(unchanged) {
+		t.Fatalf("Body() = %s, %v; want the body unchanged", got, err)
+	}
+	if _, err := Body([]byte(`{`), madrid); err == nil {
+		t.Fatal("Body() error = nil for invalid JSON")
+	}
+}
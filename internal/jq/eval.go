@@ -0,0 +1,864 @@
+package jq
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// node is an expression; eval returns its outputs for one input, like a jq filter.
+type node interface {
+	eval(input any) ([]any, error)
+}
+
+type identityNode struct{}
+
+func (identityNode) eval(input any) ([]any, error) {
+	return []any{input}, nil
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n literalNode) eval(any) ([]any, error) {
+	return []any{n.value}, nil
+}
+
+type fieldNode struct {
+	target node
+	name   string
+}
+
+func (n fieldNode) eval(input any) ([]any, error) {
+	targets, err := n.target.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]any, 0, len(targets))
+	for _, target := range targets {
+		value, err := field(target, n.name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, value)
+	}
+	return out, nil
+}
+
+func field(target any, name string) (any, error) {
+	switch v := target.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return v[name], nil
+	default:
+		return nil, fmt.Errorf("cannot index %s with %q", typeName(target), name)
+	}
+}
+
+type indexNode struct {
+	target node
+	index  node
+}
+
+func (n indexNode) eval(input any) ([]any, error) {
+	targets, err := n.target.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	// As in jq, the index expression is evaluated against the original input.
+	indexes, err := n.index.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []any
+	for _, target := range targets {
+		for _, index := range indexes {
+			switch i := index.(type) {
+			case string:
+				value, err := field(target, i)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, value)
+			case float64:
+				value, err := element(target, int(math.Floor(i)))
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, value)
+			default:
+				return nil, fmt.Errorf("cannot index %s with %s", typeName(target), typeName(index))
+			}
+		}
+	}
+	return out, nil
+}
+
+func element(target any, index int) (any, error) {
+	switch v := target.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 || index >= len(v) {
+			return nil, nil
+		}
+		return v[index], nil
+	default:
+		return nil, fmt.Errorf("cannot index %s with a number", typeName(target))
+	}
+}
+
+type sliceNode struct {
+	target   node
+	from, to node
+}
+
+func (n sliceNode) eval(input any) ([]any, error) {
+	targets, err := n.target.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	from, err := sliceBound(n.from, input)
+	if err != nil {
+		return nil, err
+	}
+	to, err := sliceBound(n.to, input)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]any, 0, len(targets))
+	for _, target := range targets {
+		switch v := target.(type) {
+		case nil:
+			out = append(out, nil)
+		case []any:
+			start, end := sliceRange(len(v), from, to)
+			out = append(out, append([]any{}, v[start:end]...))
+		case string:
+			runes := []rune(v)
+			start, end := sliceRange(len(runes), from, to)
+			out = append(out, string(runes[start:end]))
+		default:
+			return nil, fmt.Errorf("cannot slice %s", typeName(target))
+		}
+	}
+	return out, nil
+}
+
+func sliceBound(bound node, input any) (*int, error) {
+	if bound == nil {
+		return nil, nil
+	}
+	values, err := bound.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("slice bounds must produce a single number")
+	}
+	number, ok := values[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("slice bounds must be numbers, not %s", typeName(values[0]))
+	}
+	i := int(math.Floor(number))
+	return &i, nil
+}
+
+func sliceRange(length int, from, to *int) (int, int) {
+	clamp := func(i int) int {
+		if i < 0 {
+			i += length
+		}
+		return min(max(i, 0), length)
+	}
+
+	start, end := 0, length
+	if from != nil {
+		start = clamp(*from)
+	}
+	if to != nil {
+		end = clamp(*to)
+	}
+	return start, max(start, end)
+}
+
+type iterateNode struct {
+	target node
+}
+
+func (n iterateNode) eval(input any) ([]any, error) {
+	targets, err := n.target.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []any
+	for _, target := range targets {
+		values, err := iterate(target)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, values...)
+	}
+	return out, nil
+}
+
+// iterate returns the elements of an array or the values of an object ordered by key.
+func iterate(target any) ([]any, error) {
+	switch v := target.(type) {
+	case []any:
+		return v, nil
+	case map[string]any:
+		values := make([]any, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			values = append(values, v[key])
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("cannot iterate over %s", typeName(target))
+	}
+}
+
+type pipeNode struct {
+	left, right node
+}
+
+func (n pipeNode) eval(input any) ([]any, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []any
+	for _, left := range lefts {
+		rights, err := n.right.eval(left)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rights...)
+	}
+	return out, nil
+}
+
+type commaNode struct {
+	left, right node
+}
+
+func (n commaNode) eval(input any) ([]any, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	return append(lefts, rights...), nil
+}
+
+type arrayNode struct {
+	inner node
+}
+
+func (n arrayNode) eval(input any) ([]any, error) {
+	if n.inner == nil {
+		return []any{[]any{}}, nil
+	}
+	values, err := n.inner.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = []any{}
+	}
+	return []any{values}, nil
+}
+
+type objectEntry struct {
+	key, value node
+}
+
+type objectNode struct {
+	entries []objectEntry
+}
+
+// eval builds one object per combination of key and value outputs, as jq does.
+func (n objectNode) eval(input any) ([]any, error) {
+	objects := []map[string]any{{}}
+	for _, entry := range n.entries {
+		keys, err := entry.key.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		values, err := entry.value.eval(input)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []map[string]any
+		for _, object := range objects {
+			for _, key := range keys {
+				name, ok := key.(string)
+				if !ok {
+					return nil, fmt.Errorf("object keys must be strings, not %s", typeName(key))
+				}
+				for _, value := range values {
+					copied := make(map[string]any, len(object)+1)
+					for k, v := range object {
+						copied[k] = v
+					}
+					copied[name] = value
+					next = append(next, copied)
+				}
+			}
+		}
+		objects = next
+	}
+
+	out := make([]any, len(objects))
+	for i, object := range objects {
+		out[i] = object
+	}
+	return out, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(input any) ([]any, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []any
+	for _, right := range rights {
+		for _, left := range lefts {
+			c := compare(left, right)
+			var result bool
+			switch n.op {
+			case "==":
+				result = c == 0
+			case "!=":
+				result = c != 0
+			case "<":
+				result = c < 0
+			case "<=":
+				result = c <= 0
+			case ">":
+				result = c > 0
+			case ">=":
+				result = c >= 0
+			}
+			out = append(out, result)
+		}
+	}
+	return out, nil
+}
+
+type logicNode struct {
+	and         bool
+	left, right node
+}
+
+func (n logicNode) eval(input any) ([]any, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []any
+	for _, left := range lefts {
+		if truthy(left) != n.and {
+			// false and ... / true or ... short-circuit.
+			out = append(out, !n.and)
+			continue
+		}
+		rights, err := n.right.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, right := range rights {
+			out = append(out, truthy(right))
+		}
+	}
+	return out, nil
+}
+
+type callNode struct {
+	name string
+	fn   func(input any, args []node) ([]any, error)
+	args []node
+}
+
+func (n callNode) eval(input any) ([]any, error) {
+	out, err := n.fn(input, n.args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return out, nil
+}
+
+type function struct {
+	arity int
+	call  func(input any, args []node) ([]any, error)
+}
+
+var functions = map[string]function{
+	"length":   {0, one(length)},
+	"keys":     {0, one(keys)},
+	"not":      {0, one(func(v any) (any, error) { return !truthy(v), nil })},
+	"empty":    {0, func(any, []node) ([]any, error) { return nil, nil }},
+	"first":    {0, one(func(v any) (any, error) { return element(v, 0) })},
+	"last":     {0, one(func(v any) (any, error) { return element(v, -1) })},
+	"add":      {0, one(add)},
+	"sort":     {0, one(func(v any) (any, error) { return sortBy(v, nil) })},
+	"unique":   {0, one(unique)},
+	"reverse":  {0, one(reverse)},
+	"min":      {0, one(func(v any) (any, error) { return extreme(v, -1) })},
+	"max":      {0, one(func(v any) (any, error) { return extreme(v, 1) })},
+	"type":     {0, one(func(v any) (any, error) { return typeName(v), nil })},
+	"tostring": {0, one(tostring)},
+	"tonumber": {0, one(tonumber)},
+	"map":      {1, mapFn},
+	"select":   {1, selectFn},
+	"sort_by":  {1, sortByFn},
+	"has":      {1, withArg(has)},
+	"join":     {1, withArg(join)},
+}
+
+func one(fn func(any) (any, error)) func(any, []node) ([]any, error) {
+	return func(input any, _ []node) ([]any, error) {
+		value, err := fn(input)
+		if err != nil {
+			return nil, err
+		}
+		return []any{value}, nil
+	}
+}
+
+// withArg evaluates the single argument against the input and calls fn for each output.
+func withArg(fn func(input, arg any) (any, error)) func(any, []node) ([]any, error) {
+	return func(input any, args []node) ([]any, error) {
+		values, err := args[0].eval(input)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]any, 0, len(values))
+		for _, arg := range values {
+			value, err := fn(input, arg)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, value)
+		}
+		return out, nil
+	}
+}
+
+func mapFn(input any, args []node) ([]any, error) {
+	items, err := iterate(input)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]any, 0, len(items))
+	for _, item := range items {
+		values, err := args[0].eval(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, values...)
+	}
+	return []any{out}, nil
+}
+
+func selectFn(input any, args []node) ([]any, error) {
+	conditions, err := args[0].eval(input)
+	if err != nil {
+		return nil, err
+	}
+	var out []any
+	for _, condition := range conditions {
+		if truthy(condition) {
+			out = append(out, input)
+		}
+	}
+	return out, nil
+}
+
+func sortByFn(input any, args []node) ([]any, error) {
+	sorted, err := sortBy(input, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return []any{sorted}, nil
+}
+
+func length(v any) (any, error) {
+	switch v := v.(type) {
+	case nil:
+		return float64(0), nil
+	case float64:
+		return math.Abs(v), nil
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []any:
+		return float64(len(v)), nil
+	case map[string]any:
+		return float64(len(v)), nil
+	default:
+		return nil, fmt.Errorf("%s has no length", typeName(v))
+	}
+}
+
+func keys(v any) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make([]any, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			out = append(out, key)
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i := range v {
+			out[i] = float64(i)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%s has no keys", typeName(v))
+	}
+}
+
+func add(v any) (any, error) {
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var sum any
+	for _, item := range items {
+		switch acc := sum.(type) {
+		case nil:
+			sum = item
+		case float64:
+			n, ok := item.(float64)
+			if !ok && item != nil {
+				return nil, fmt.Errorf("cannot add number and %s", typeName(item))
+			}
+			sum = acc + n
+		case string:
+			s, ok := item.(string)
+			if !ok && item != nil {
+				return nil, fmt.Errorf("cannot add string and %s", typeName(item))
+			}
+			sum = acc + s
+		case []any:
+			a, ok := item.([]any)
+			if !ok && item != nil {
+				return nil, fmt.Errorf("cannot add array and %s", typeName(item))
+			}
+			sum = append(append([]any{}, acc...), a...)
+		case map[string]any:
+			m, ok := item.(map[string]any)
+			if !ok && item != nil {
+				return nil, fmt.Errorf("cannot add object and %s", typeName(item))
+			}
+			merged := make(map[string]any, len(acc)+len(m))
+			for k, val := range acc {
+				merged[k] = val
+			}
+			for k, val := range m {
+				merged[k] = val
+			}
+			sum = merged
+		default:
+			return nil, fmt.Errorf("cannot add %s", typeName(acc))
+		}
+	}
+	return sum, nil
+}
+
+// sortBy sorts an array by the value of by (or the items themselves when by is nil).
+func sortBy(v any, by node) (any, error) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot sort %s", typeName(v))
+	}
+
+	sortKeys := make([]any, len(items))
+	for i, item := range items {
+		sortKeys[i] = item
+		if by != nil {
+			values, err := by.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			sortKeys[i] = any(values)
+		}
+	}
+
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return compare(sortKeys[indexes[i]], sortKeys[indexes[j]]) < 0
+	})
+
+	sorted := make([]any, len(items))
+	for i, index := range indexes {
+		sorted[i] = items[index]
+	}
+	return sorted, nil
+}
+
+func unique(v any) (any, error) {
+	sorted, err := sortBy(v, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []any
+	for _, item := range sorted.([]any) {
+		if len(out) == 0 || compare(out[len(out)-1], item) != 0 {
+			out = append(out, item)
+		}
+	}
+	if out == nil {
+		out = []any{}
+	}
+	return out, nil
+}
+
+func reverse(v any) (any, error) {
+	switch v := v.(type) {
+	case nil:
+		return []any{}, nil
+	case string:
+		runes := []rune(v)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[len(v)-1-i] = item
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("cannot reverse %s", typeName(v))
+	}
+}
+
+// extreme returns the minimum (sign -1) or maximum (sign 1) of an array, or null when empty.
+func extreme(v any, sign int) (any, error) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s has no min or max", typeName(v))
+	}
+	var best any
+	for i, item := range items {
+		if i == 0 || compare(item, best)*sign > 0 {
+			best = item
+		}
+	}
+	return best, nil
+}
+
+func tostring(v any) (any, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+func tonumber(v any) (any, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %q as a number", v)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a number", typeName(v))
+	}
+}
+
+func has(input, key any) (any, error) {
+	switch v := input.(type) {
+	case map[string]any:
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot check whether an object has a %s key", typeName(key))
+		}
+		_, found := v[name]
+		return found, nil
+	case []any:
+		index, ok := key.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot check whether an array has a %s key", typeName(key))
+		}
+		return index >= 0 && int(index) < len(v), nil
+	default:
+		return nil, fmt.Errorf("cannot check whether %s has a key", typeName(input))
+	}
+}
+
+func join(input, separator any) (any, error) {
+	sep, ok := separator.(string)
+	if !ok {
+		return nil, fmt.Errorf("separator must be a string, not %s", typeName(separator))
+	}
+	items, ok := input.([]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot join %s", typeName(input))
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case nil:
+		case string:
+			parts[i] = v
+		case float64, bool:
+			s, _ := tostring(v)
+			parts[i] = s.(string)
+		default:
+			return nil, fmt.Errorf("cannot join %s", typeName(item))
+		}
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	default:
+		return true
+	}
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// typeOrder ranks types as jq sorts them: null < false < true < numbers < strings < arrays < objects.
+func typeOrder(v any) int {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 2
+		}
+		return 1
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []any:
+		return 5
+	default:
+		return 6
+	}
+}
+
+func compare(a, b any) int {
+	if ta, tb := typeOrder(a), typeOrder(b); ta != tb {
+		return cmp.Compare(ta, tb)
+	}
+
+	switch a := a.(type) {
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case string:
+		return strings.Compare(a, b.(string))
+	case []any:
+		b := b.([]any)
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if c := compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(len(a), len(b))
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok {
+			return 0
+		}
+		ka, kb := sortedKeys(a), sortedKeys(b)
+		if c := compare(stringsToAny(ka), stringsToAny(kb)); c != 0 {
+			return c
+		}
+		for _, key := range ka {
+			if c := compare(a[key], b[key]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringsToAny(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
@@ -0,0 +1,457 @@
+// Package jq evaluates a subset of the jq language over decoded JSON values
+// (nil, bool, float64, string, []any and map[string]any).
+//
+// Supported: identity (.), field access (.a.b, ."a b", .["a"]), indexes and slices
+// (.[0], .[-1], .[1:3]), iteration (.[]), pipes (|), multiple outputs (,), array and
+// object construction ([...], {id, name: .contact.name}), literals, comparisons
+// (== != < <= > >=), and/or, parentheses and the functions length, keys, map, select,
+// not, empty, first, last, add, sort, sort_by, unique, reverse, min, max, type,
+// tostring, tonumber, has and join.
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed expression.
+type Query struct {
+	root node
+}
+
+// Parse compiles expr.
+func Parse(expr string) (*Query, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+	}
+	return &Query{root: root}, nil
+}
+
+// Run evaluates the query against input and returns every output, in order.
+func (q *Query) Run(input any) ([]any, error) {
+	return q.root.eval(input)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokDot
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	// value is the decoded string or number literal.
+	value any
+}
+
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '.':
+			tokens = append(tokens, token{kind: tokDot, text: ".", pos: i})
+			i++
+		case strings.ContainsRune("[]{}(),:;|", rune(c)):
+			tokens = append(tokens, token{kind: tokPunct, text: string(c), pos: i})
+			i++
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(expr) && expr[i+1] == '=' {
+				op += "="
+			}
+			if op == "=" || op == "!" {
+				return nil, fmt.Errorf("unexpected %q at offset %d", op, i)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		case c == '-':
+			tokens = append(tokens, token{kind: tokOp, text: "-", pos: i})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(expr) && expr[end] != '"'; end++ {
+				if expr[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			var s string
+			if err := json.Unmarshal([]byte(expr[i:end+1]), &s); err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %v", i, err)
+			}
+			tokens = append(tokens, token{kind: tokString, text: expr[i : end+1], pos: i, value: s})
+			i = end + 1
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(expr) && (expr[end] >= '0' && expr[end] <= '9' || expr[end] == '.' || expr[end] == 'e' || expr[end] == 'E') {
+				end++
+			}
+			n, err := strconv.ParseFloat(expr[i:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at offset %d", expr[i:end], i)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: expr[i:end], pos: i, value: n})
+			i = end
+		case c == '_' || c == '$' || unicode.IsLetter(rune(c)):
+			end := i
+			for end < len(expr) && (expr[end] == '_' || unicode.IsLetter(rune(expr[end])) || unicode.IsDigit(rune(expr[end]))) {
+				end++
+			}
+			if c == '$' {
+				return nil, fmt.Errorf("variables are not supported (offset %d)", i)
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: expr[i:end], pos: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", string(c), i)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "end of expression", pos: len(expr)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isPunct(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokPunct && tok.text == text
+}
+
+func (p *parser) expect(text string) error {
+	tok := p.next()
+	if tok.kind != tokPunct || tok.text != text {
+		return fmt.Errorf("expected %q, found %q at offset %d", text, tok.text, tok.pos)
+	}
+	return nil
+}
+
+// adjacent reports whether the next token starts right after the previous one, so that
+// ".foo" is a field access but ". foo" is not.
+func (p *parser) adjacent() bool {
+	prev := p.tokens[p.pos-1]
+	return p.peek().pos == prev.pos+len(prev.text)
+}
+
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("|") {
+		p.next()
+		right, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		left = pipeNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComma() (node, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct(",") {
+		p.next()
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		left = commaNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokIdent && tok.text == "or"; tok = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokIdent && tok.text == "and"; tok = p.peek() {
+		p.next()
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind == tokOp && tok.text != "-" {
+		p.next()
+		right, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: tok.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.peek().kind == tokDot && p.adjacent():
+			p.next()
+			name, ok := p.fieldName()
+			if !ok {
+				if !p.isPunct("[") {
+					tok := p.peek()
+					return nil, fmt.Errorf("expected a field name after '.', found %q at offset %d", tok.text, tok.pos)
+				}
+				continue
+			}
+			target = fieldNode{target: target, name: name}
+		case p.isPunct("["):
+			target, err = p.parseBracket(target)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return target, nil
+		}
+	}
+}
+
+// fieldName consumes an identifier or string directly after a dot.
+func (p *parser) fieldName() (string, bool) {
+	tok := p.peek()
+	if !p.adjacent() || (tok.kind != tokIdent && tok.kind != tokString) {
+		return "", false
+	}
+	p.next()
+	if tok.kind == tokString {
+		return tok.value.(string), true
+	}
+	return tok.text, true
+}
+
+// parseBracket parses [], [expr] and [from:to] after target.
+func (p *parser) parseBracket(target node) (node, error) {
+	p.next() // [
+	if p.isPunct("]") {
+		p.next()
+		return iterateNode{target: target}, nil
+	}
+
+	var from, to node
+	var err error
+	if !p.isPunct(":") {
+		if from, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.isPunct(":") {
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return indexNode{target: target, index: from}, nil
+	}
+
+	p.next() // :
+	if !p.isPunct("]") {
+		if to, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return sliceNode{target: target, from: from, to: to}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokDot:
+		if name, ok := p.fieldName(); ok {
+			return fieldNode{target: identityNode{}, name: name}, nil
+		}
+		return identityNode{}, nil
+	case tokString, tokNumber:
+		return literalNode{value: tok.value}, nil
+	case tokOp:
+		if tok.text == "-" && p.peek().kind == tokNumber {
+			return literalNode{value: -p.next().value.(float64)}, nil
+		}
+	case tokIdent:
+		return p.parseIdent(tok)
+	case tokPunct:
+		switch tok.text {
+		case "(":
+			inner, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			if p.isPunct("]") {
+				p.next()
+				return arrayNode{}, nil
+			}
+			inner, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return arrayNode{inner: inner}, p.expect("]")
+		case "{":
+			return p.parseObject()
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+}
+
+func (p *parser) parseIdent(tok token) (node, error) {
+	switch tok.text {
+	case "true":
+		return literalNode{value: true}, nil
+	case "false":
+		return literalNode{value: false}, nil
+	case "null":
+		return literalNode{value: nil}, nil
+	}
+
+	fn, ok := functions[tok.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at offset %d", tok.text, tok.pos)
+	}
+
+	var args []node
+	if p.isPunct("(") {
+		p.next()
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.isPunct(";") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf("%s expects %d argument(s), got %d (offset %d)", tok.text, fn.arity, len(args), tok.pos)
+	}
+	return callNode{name: tok.text, fn: fn.call, args: args}, nil
+}
+
+func (p *parser) parseObject() (node, error) {
+	var entries []objectEntry
+	for !p.isPunct("}") {
+		if len(entries) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		var entry objectEntry
+		tok := p.next()
+		switch {
+		case tok.kind == tokIdent || tok.kind == tokString:
+			name := tok.text
+			if tok.kind == tokString {
+				name = tok.value.(string)
+			}
+			entry.key = literalNode{value: name}
+			entry.value = fieldNode{target: identityNode{}, name: name}
+		case tok.kind == tokPunct && tok.text == "(":
+			key, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			entry.key = key
+		default:
+			return nil, fmt.Errorf("unexpected %q in object at offset %d", tok.text, tok.pos)
+		}
+
+		if p.isPunct(":") {
+			p.next()
+			value, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			entry.value = value
+		} else if entry.value == nil {
+			return nil, fmt.Errorf("expected ':' after computed key at offset %d", p.peek().pos)
+		}
+		entries = append(entries, entry)
+	}
+	p.next() // }
+	return objectNode{entries: entries}, nil
+}
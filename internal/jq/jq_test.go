@@ -0,0 +1,101 @@
+package jq
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const documents = `{"items":[
+	{"id":"d1","contact":{"name":"Acme"},"total":121.5,"status":1,"tags":["a","b"]},
+	{"id":"d2","contact":{"name":"Globex"},"total":10,"status":0,"tags":[]},
+	{"id":"d3","contact":null,"total":50,"status":1}
+]}`
+
+func TestQueryRun(t *testing.T) {
+	t.Parallel()
+
+	var input any
+	if err := json.Unmarshal([]byte(documents), &input); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: `.`, want: documents},
+		{expr: `.items[].id`, want: `["d1","d2","d3"]`},
+		{expr: `.items[0].contact.name`, want: `["Acme"]`},
+		{expr: `.items[-1].id`, want: `["d3"]`},
+		{expr: `.items[2].contact.name`, want: `[null]`},
+		{expr: `.items[1:] | map(.id)`, want: `[["d2","d3"]]`},
+		{expr: `.["items"] | length`, want: `[3]`},
+		{expr: `."items"[0]."id"`, want: `["d1"]`},
+		{expr: `[.items[] | select(.status == 1) | .id]`, want: `[["d1","d3"]]`},
+		{expr: `.items[] | select(.total > 20 and .contact != null) | .id`, want: `["d1"]`},
+		{expr: `.items[] | select(.total < 20 or .status == 0) | .id`, want: `["d2"]`},
+		{expr: `.items[] | select(.tags | not) | .id`, want: `["d3"]`},
+		{expr: `.items | map(.total) | add`, want: `[181.5]`},
+		{expr: `.items | map(.total) | max, min`, want: `[121.5,10]`},
+		{expr: `.items | sort_by(.total) | map(.id)`, want: `[["d2","d3","d1"]]`},
+		{expr: `.items | map(.status) | unique`, want: `[[0,1]]`},
+		{expr: `.items[0] | keys`, want: `[["contact","id","status","tags","total"]]`},
+		{expr: `.items[0] | {id, name: .contact.name, "total"}`, want: `[{"id":"d1","name":"Acme","total":121.5}]`},
+		{expr: `.items[0] | {(.id): .total}`, want: `[{"d1":121.5}]`},
+		{expr: `.items[0].tags | join(", ")`, want: `["a, b"]`},
+		{expr: `.items[0] | has("tags"), has("missing")`, want: `[true,false]`},
+		{expr: `.items[0].total | tostring`, want: `["121.5"]`},
+		{expr: `"42" | tonumber`, want: `[42]`},
+		{expr: `.items | first.id, last.id`, want: `["d1","d3"]`},
+		{expr: `.items[0].tags | reverse`, want: `[["b","a"]]`},
+		{expr: `.items[].contact | type`, want: `["object","object","null"]`},
+		{expr: `.items[] | empty`, want: `null`},
+		{expr: `[-1, 2.5, true, null, "x", []]`, want: `[[-1,2.5,true,null,"x",[]]]`},
+		{expr: `.missing.deeper`, want: `[null]`},
+	}
+
+	for _, tt := range tests {
+		query, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+		}
+		got, err := query.Run(input)
+		if err != nil {
+			t.Fatalf("Run(%q) error = %v", tt.expr, err)
+		}
+
+		var want any
+		if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+			t.Fatalf("bad want %q: %v", tt.want, err)
+		}
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		if tt.expr == "." {
+			gotJSON, _ = json.Marshal(got[0])
+		}
+		if string(gotJSON) != string(wantJSON) {
+			t.Fatalf("Run(%q) = %s, want %s", tt.expr, gotJSON, wantJSON)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{``, `.items[`, `.items |`, `foo`, `length(1)`, `map`, `$x`, `.a = 1`, `{(.a)}`, `"unterminated`} {
+		if _, err := Parse(expr); err == nil {
+			t.Fatalf("Parse(%q) error = nil", expr)
+		}
+	}
+
+	var input any = map[string]any{"id": "d1", "n": float64(1)}
+	for _, expr := range []string{`.id.name`, `.n[]`, `.id[0]`, `.n | keys`} {
+		query, err := Parse(expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", expr, err)
+		}
+		if _, err := query.Run(input); err == nil {
+			t.Fatalf("Run(%q) error = nil", expr)
+		}
+	}
+}
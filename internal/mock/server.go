@@ -0,0 +1,348 @@
+// Package mock serves an in-memory imitation of the Holded API built from the action
+// catalog, so commands and scripts can be tried without touching real data.
+package mock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaumecornado/holdedcli/internal/actions"
+	"github.com/jaumecornado/holdedcli/internal/holded"
+)
+
+// maxBodySize caps request bodies read by the server.
+const maxBodySize = 10 << 20
+
+// samplePDF is returned, base64 encoded, by actions whose path ends in /pdf.
+var samplePDF = []byte("%PDF-1.4\n%holded mock document\n%%EOF\n")
+
+// Server implements every catalog action against an in-memory store. Records live in
+// collections keyed by the resolved path without the record ID, so
+// POST /api/invoicing/v1/documents/invoice creates a record that
+// GET /api/invoicing/v1/documents/invoice/{documentId} returns.
+type Server struct {
+	apiKey string
+	routes []route
+	log    io.Writer
+
+	mu          sync.Mutex
+	collections map[string]*collection
+	nextID      int
+}
+
+type route struct {
+	action   actions.Action
+	segments []string
+}
+
+type collection struct {
+	ids     []string
+	records map[string]map[string]any
+}
+
+// NewServer returns a server for the catalog's actions. Requests must send apiKey in
+// the key header; an empty apiKey accepts any non-empty key.
+func NewServer(catalog actions.Catalog, apiKey string) *Server {
+	s := &Server{
+		apiKey:      strings.TrimSpace(apiKey),
+		log:         io.Discard,
+		collections: make(map[string]*collection),
+	}
+	for _, action := range catalog.Actions {
+		s.routes = append(s.routes, route{action: action, segments: splitPath(action.Path)})
+	}
+	return s
+}
+
+// SetLogger writes one line per request to w.
+func (s *Server) SetLogger(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	s.log = w
+}
+
+// Routes returns the number of actions served.
+func (s *Server) Routes() int {
+	return len(s.routes)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	started := time.Now()
+	s.serve(recorder, r)
+	fmt.Fprintf(s.log, "%s %s %s -> %d (%s)\n",
+		started.Format("15:04:05"), r.Method, r.URL.RequestURI(), recorder.status, time.Since(started).Round(time.Microsecond))
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSpace(r.Header.Get(holded.APIKeyHeader))
+	if key == "" || (s.apiKey != "" && key != s.apiKey) {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"status": 0, "info": "Invalid API key"})
+		return
+	}
+
+	segments := splitPath(r.URL.Path)
+	rt, ok := s.match(r.Method, segments)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"status": 0, "info": fmt.Sprintf("no action for %s %s", r.Method, r.URL.Path)})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"status": 0, "info": fmt.Sprintf("reading body: %v", err)})
+		return
+	}
+
+	multipart := isMultipart(r.Header.Get("Content-Type"))
+	// Actions without body metadata (NoMetadata, as in the embedded catalog) accept any
+	// body; the others reject unknown fields, wrong types and missing required fields.
+	if !multipart {
+		if issues := actions.ValidateBodyParameters(rt.action, body); len(issues) > 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": 0, "info": "Validation failed", "errors": issues})
+			return
+		}
+	}
+
+	var fields map[string]any
+	if !multipart && len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &fields); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": 0, "info": "request body must be a JSON object"})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Every ID in the path before the last segment must name an existing record:
+	// /documents/invoice/{documentId}/pay needs the invoice.
+	last := len(segments) - 1
+	for i := 0; i < last; i++ {
+		if isIDParam(rt.segments[i]) {
+			if _, ok := s.lookup(joinPath(segments[:i]), segments[i]); !ok {
+				writeNotFound(w)
+				return
+			}
+		}
+	}
+
+	if isIDParam(rt.segments[last]) {
+		s.serveRecord(w, r, joinPath(segments[:last]), segments[last], fields)
+		return
+	}
+	s.serveCollection(w, r, joinPath(segments), segments[last], fields)
+}
+
+// serveRecord handles actions whose path ends in a record ID.
+func (s *Server) serveRecord(w http.ResponseWriter, r *http.Request, key, id string, fields map[string]any) {
+	record, ok := s.lookup(key, id)
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, record)
+	case http.MethodDelete:
+		c := s.collections[key]
+		delete(c.records, id)
+		for i, existing := range c.ids {
+			if existing == id {
+				c.ids = append(c.ids[:i], c.ids[i+1:]...)
+				break
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": 1, "info": "Deleted", "id": id})
+	default:
+		for name, value := range fields {
+			if name != "id" {
+				record[name] = value
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": 1, "info": "Updated", "id": id})
+	}
+}
+
+// serveCollection lists records on GET and creates one on POST or PUT.
+func (s *Server) serveCollection(w http.ResponseWriter, r *http.Request, key, name string, fields map[string]any) {
+	switch r.Method {
+	case http.MethodGet:
+		if name == "pdf" {
+			writeJSON(w, http.StatusOK, map[string]any{"status": 1, "data": base64.StdEncoding.EncodeToString(samplePDF)})
+			return
+		}
+		records, err := s.page(key, r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"status": 0, "info": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, records)
+	case http.MethodDelete:
+		writeJSON(w, http.StatusOK, map[string]any{"status": 1, "info": "Deleted"})
+	default:
+		s.nextID++
+		id := fmt.Sprintf("%024x", s.nextID)
+		record := map[string]any{"id": id}
+		for name, value := range fields {
+			if name != "id" {
+				record[name] = value
+			}
+		}
+		c, ok := s.collections[key]
+		if !ok {
+			c = &collection{records: make(map[string]map[string]any)}
+			s.collections[key] = c
+		}
+		c.ids = append(c.ids, id)
+		c.records[id] = record
+		writeJSON(w, http.StatusOK, map[string]any{"status": 1, "info": "Created", "id": id})
+	}
+}
+
+// page returns the collection's records in creation order. With a limit query parameter
+// it returns that page; without one, page 1 holds every record.
+func (s *Server) page(key string, query map[string][]string) ([]map[string]any, error) {
+	page, err := positiveParam(query, holded.PageParam, 1)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := positiveParam(query, holded.PageSizeParam, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []map[string]any{}
+	c, ok := s.collections[key]
+	if !ok {
+		return records, nil
+	}
+	ids := c.ids
+	switch {
+	case limit > 0:
+		start := (page - 1) * limit
+		if start >= len(ids) {
+			return records, nil
+		}
+		ids = ids[start:min(start+limit, len(ids))]
+	case page > 1:
+		return records, nil
+	}
+	for _, id := range ids {
+		records = append(records, c.records[id])
+	}
+	return records, nil
+}
+
+func (s *Server) lookup(key, id string) (map[string]any, bool) {
+	c, ok := s.collections[key]
+	if !ok {
+		return nil, false
+	}
+	record, ok := c.records[id]
+	return record, ok
+}
+
+// match returns the action for method and path. When several templates match, the one
+// with the most literal segments wins, so /contacts/attachments beats /contacts/{contactId}.
+func (s *Server) match(method string, segments []string) (route, bool) {
+	var best route
+	bestScore := -1
+	for _, rt := range s.routes {
+		if !strings.EqualFold(rt.action.Method, method) || len(rt.segments) != len(segments) || len(segments) == 0 {
+			continue
+		}
+		score := 0
+		matched := true
+		for i, segment := range rt.segments {
+			if isParam(segment) {
+				continue
+			}
+			if segment != segments[i] {
+				matched = false
+				break
+			}
+			score++
+		}
+		if matched && score > bestScore {
+			best, bestScore = rt, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+func splitPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+func joinPath(segments []string) string {
+	return "/" + strings.Join(segments, "/")
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// isIDParam reports whether a template segment names a record ({contactId}), as opposed
+// to a selector such as {docType}.
+func isIDParam(segment string) bool {
+	if !isParam(segment) {
+		return false
+	}
+	name := strings.ToLower(strings.Trim(segment, "{}"))
+	return strings.HasSuffix(name, "id")
+}
+
+func isMultipart(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && strings.HasPrefix(mediaType, "multipart/")
+}
+
+func positiveParam(query map[string][]string, name string, fallback int) (int, error) {
+	values := query[name]
+	if len(values) == 0 || values[0] == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(values[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return n, nil
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]any{"status": 0, "info": "Not found"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
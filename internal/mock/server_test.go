@@ -0,0 +1,198 @@
+package mock
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jaumecornado/holdedcli/internal/actions"
+)
+
+func testCatalog() actions.Catalog {
+	return actions.Catalog{Actions: []actions.Action{
+		{ID: "invoice.list-contacts", Method: "GET", Path: "/api/invoicing/v1/contacts"},
+		{ID: "invoice.create-contact", Method: "POST", Path: "/api/invoicing/v1/contacts", RequestBody: &actions.ActionRequestBody{
+			Required: true,
+			Fields:   []actions.ActionBodyField{{Name: "name", Type: "string", Required: true}},
+		}},
+		{ID: "invoice.get-contact", Method: "GET", Path: "/api/invoicing/v1/contacts/{contactId}"},
+		{ID: "invoice.update-contact", Method: "PUT", Path: "/api/invoicing/v1/contacts/{contactId}", NoMetadata: true},
+		{ID: "invoice.delete-contact", Method: "DELETE", Path: "/api/invoicing/v1/contacts/{contactId}"},
+		{ID: "invoice.list-documents", Method: "GET", Path: "/api/invoicing/v1/documents/{docType}"},
+		{ID: "invoice.create-document", Method: "POST", Path: "/api/invoicing/v1/documents/{docType}", NoMetadata: true},
+		{ID: "invoice.pay-document", Method: "POST", Path: "/api/invoicing/v1/documents/{docType}/{documentId}/pay", NoMetadata: true},
+		{ID: "invoice.getdocumentpdf", Method: "GET", Path: "/api/invoicing/v1/documents/{docType}/{documentId}/pdf"},
+	}}
+}
+
+type call struct {
+	status int
+	body   map[string]any
+	list   []map[string]any
+}
+
+func do(t *testing.T, srv *httptest.Server, key, method, path, body string) call {
+	t.Helper()
+
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		req.Header.Set("key", key)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+
+	result := call{status: resp.StatusCode}
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		err = json.Unmarshal(raw, &result.list)
+	} else {
+		err = json.Unmarshal(raw, &result.body)
+	}
+	if err != nil {
+		t.Fatalf("%s %s: invalid JSON %q: %v", method, path, raw, err)
+	}
+	return result
+}
+
+func TestServerCRUD(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(NewServer(testCatalog(), "secret"))
+	defer srv.Close()
+	const contacts = "/api/invoicing/v1/contacts"
+
+	if got := do(t, srv, "", "GET", contacts, ""); got.status != http.StatusUnauthorized {
+		t.Fatalf("missing key status = %d", got.status)
+	}
+	if got := do(t, srv, "wrong", "GET", contacts, ""); got.status != http.StatusUnauthorized {
+		t.Fatalf("wrong key status = %d", got.status)
+	}
+
+	invalid := do(t, srv, "secret", "POST", contacts, `{}`)
+	errs, _ := invalid.body["errors"].([]any)
+	if invalid.status != http.StatusBadRequest || len(errs) != 1 {
+		t.Fatalf("invalid create = %d %v", invalid.status, invalid.body)
+	}
+	if field := errs[0].(map[string]any)["field"]; field != "$.name" {
+		t.Fatalf("validation field = %v", field)
+	}
+
+	first := do(t, srv, "secret", "POST", contacts, `{"name":"Acme"}`)
+	second := do(t, srv, "secret", "POST", contacts, `{"name":"Globex"}`)
+	id, _ := first.body["id"].(string)
+	if first.status != http.StatusOK || first.body["status"] != float64(1) || id == "" || id == second.body["id"] {
+		t.Fatalf("create = %d %v / %v", first.status, first.body, second.body)
+	}
+
+	if got := do(t, srv, "secret", "PUT", contacts+"/"+id, `{"name":"Acme Corp","id":"ignored"}`); got.status != http.StatusOK {
+		t.Fatalf("update status = %d", got.status)
+	}
+	got := do(t, srv, "secret", "GET", contacts+"/"+id, "")
+	if got.status != http.StatusOK || got.body["name"] != "Acme Corp" || got.body["id"] != id {
+		t.Fatalf("get = %d %v", got.status, got.body)
+	}
+
+	if list := do(t, srv, "secret", "GET", contacts, ""); len(list.list) != 2 || list.list[0]["name"] != "Acme Corp" {
+		t.Fatalf("list = %v", list.list)
+	}
+	if page := do(t, srv, "secret", "GET", contacts+"?page=2&limit=1", ""); len(page.list) != 1 || page.list[0]["name"] != "Globex" {
+		t.Fatalf("page 2 = %v", page.list)
+	}
+	if page := do(t, srv, "secret", "GET", contacts+"?page=2", ""); len(page.list) != 0 {
+		t.Fatalf("page 2 without limit = %v", page.list)
+	}
+
+	if got := do(t, srv, "secret", "DELETE", contacts+"/"+id, ""); got.status != http.StatusOK {
+		t.Fatalf("delete status = %d", got.status)
+	}
+	if got := do(t, srv, "secret", "GET", contacts+"/"+id, ""); got.status != http.StatusNotFound {
+		t.Fatalf("get after delete status = %d", got.status)
+	}
+	if got := do(t, srv, "secret", "PATCH", contacts, ""); got.status != http.StatusNotFound {
+		t.Fatalf("unknown route status = %d", got.status)
+	}
+}
+
+func TestServerNestedResources(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(NewServer(testCatalog(), ""))
+	defer srv.Close()
+
+	created := do(t, srv, "any", "POST", "/api/invoicing/v1/documents/invoice", `{"contactId":"c1"}`)
+	id, _ := created.body["id"].(string)
+
+	// Collections are per docType.
+	if list := do(t, srv, "any", "GET", "/api/invoicing/v1/documents/invoice", ""); len(list.list) != 1 {
+		t.Fatalf("invoices = %v", list.list)
+	}
+	if list := do(t, srv, "any", "GET", "/api/invoicing/v1/documents/estimate", ""); list.list == nil || len(list.list) != 0 {
+		t.Fatalf("estimates = %v", list.list)
+	}
+
+	if got := do(t, srv, "any", "POST", "/api/invoicing/v1/documents/invoice/"+id+"/pay", `{"amount":10}`); got.status != http.StatusOK {
+		t.Fatalf("pay = %d %v", got.status, got.body)
+	}
+	if got := do(t, srv, "any", "POST", "/api/invoicing/v1/documents/invoice/missing/pay", `{"amount":10}`); got.status != http.StatusNotFound {
+		t.Fatalf("pay missing document = %d", got.status)
+	}
+
+	pdf := do(t, srv, "any", "GET", "/api/invoicing/v1/documents/invoice/"+id+"/pdf", "")
+	decoded, err := base64.StdEncoding.DecodeString(pdf.body["data"].(string))
+	if err != nil || !strings.HasPrefix(string(decoded), "%PDF-") {
+		t.Fatalf("pdf = %v, %v", pdf.body, err)
+	}
+}
+
+func TestServerValidatesBodies(t *testing.T) {
+	t.Parallel()
+
+	catalog := actions.Catalog{Actions: []actions.Action{
+		{ID: "invoice.create-product", Method: "POST", Path: "/api/invoicing/v1/products", RequestBody: &actions.ActionRequestBody{
+			Required: true,
+			Fields: []actions.ActionBodyField{
+				{Name: "name", Type: "string", Required: true},
+				{Name: "price", Type: "number"},
+				{Name: "kind", Type: "string", Enum: []string{"simple", "pack"}},
+			},
+		}},
+		{ID: "invoice.create-service", Method: "POST", Path: "/api/invoicing/v1/services", NoMetadata: true},
+		{ID: "invoice.archive-product", Method: "POST", Path: "/api/invoicing/v1/products/{productId}/archive"},
+	}}
+	srv := httptest.NewServer(NewServer(catalog, ""))
+	defer srv.Close()
+
+	for body, want := range map[string]string{
+		`{"name":"Chair","colour":"red"}`:  "$.colour",
+		`{"name":"Chair","price":"cheap"}`: "$.price",
+		`{"name":"Chair","kind":"bundle"}`: "$.kind",
+		`{"price":10}`:                     "$.name",
+	} {
+		got := do(t, srv, "any", "POST", "/api/invoicing/v1/products", body)
+		errs, _ := got.body["errors"].([]any)
+		if got.status != http.StatusBadRequest || len(errs) != 1 || errs[0].(map[string]any)["field"] != want {
+			t.Fatalf("%s = %d %v, want an error on %s", body, got.status, got.body, want)
+		}
+	}
+	if got := do(t, srv, "any", "POST", "/api/invoicing/v1/products", `{"name":"Chair","price":10,"kind":"pack"}`); got.status != http.StatusOK {
+		t.Fatalf("valid create = %d %v", got.status, got.body)
+	}
+
+	// Actions without metadata accept any body; actions declaring none reject one.
+	if got := do(t, srv, "any", "POST", "/api/invoicing/v1/services", `{"anything":[1,2]}`); got.status != http.StatusOK {
+		t.Fatalf("create without metadata = %d %v", got.status, got.body)
+	}
+	if got := do(t, srv, "any", "POST", "/api/invoicing/v1/products/p1/archive", `{"reason":"old"}`); got.status != http.StatusBadRequest {
+		t.Fatalf("body on an action without one = %d %v", got.status, got.body)
+	}
+}